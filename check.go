@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// commandFlags returns the value of every -command and -*-command that is
+// set, by flag name.
+func commandFlags() map[string]string {
+	commands := map[string]string{}
+	flag.VisitAll(func(f *flag.Flag) {
+		if command := f.Value.String(); command != "" && (f.Name == "command" || strings.HasSuffix(f.Name, "-command")) {
+			commands[f.Name] = command
+		}
+	})
+	return commands
+}
+
+// checkCommands checks the shell syntax of every -command and -*-command
+// for -check, without running them.
+func checkCommands() error {
+	for name, command := range commandFlags() {
+		if err := checkCommand(command); err != nil {
+			return fmt.Errorf("Invalid -%v %q: %v", name, command, err)
+		}
+	}
+	return nil
+}
+
+// checkCommand checks the shell syntax of command without running it.
+func checkCommand(command string) error {
+	if command == "" {
+		return nil
+	}
+	if out, err := execCommand("/bin/sh", "-n", "-c", command).CombinedOutput(); err != nil {
+		return errors.New(strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// checkWebhook checks that -webhook is an absolute http or https URL.
+func checkWebhook() error {
+	if Webhook == "" {
+		return nil
+	}
+	u, err := url.Parse(Webhook)
+	if err == nil && (u.Scheme != "http" && u.Scheme != "https" || u.Host == "") {
+		err = fmt.Errorf("expected an http or https URL")
+	}
+	if err != nil {
+		return fmt.Errorf("Invalid -webhook %q: %v", Webhook, err)
+	}
+	return nil
+}
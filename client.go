@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// clientCommands maps the subcommands of the client to the endpoints they
+// call on a running server.
+var clientCommands = map[string]struct{ method, path string }{
+	"start":  {"POST", "/action/start"},
+	"stop":   {"POST", "/action/stop"},
+	"pause":  {"POST", "/action/pause"},
+	"resume": {"POST", "/action/resume"},
+	"skip":   {"POST", "/action/skip"},
+	"toggle": {"POST", "/action/toggle"},
+	"ack":    {"POST", "/action/ack"},
+	"status": {"GET", "/status"},
+}
+
+// runClient runs a subcommand against the server at addr and prints the
+// response. It exits the program.
+func runClient(addr string, args []string) {
+	if len(args) != 1 {
+		fatalf("Expected one command, got %q", args)
+	}
+	if _, ok := clientCommands[args[0]]; !ok {
+		fatalf("Unknown command %q", args[0])
+	}
+	out, err := clientCommand(serverURL(addr), args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+	os.Exit(0)
+}
+
+// clientCommand calls the endpoint of command on the server at baseURL and
+// returns the response body.
+func clientCommand(baseURL, command string) (string, error) {
+	c, ok := clientCommands[command]
+	if !ok {
+		return "", fmt.Errorf("Unknown command %q", command)
+	}
+	req, err := http.NewRequest(c.method, strings.TrimSuffix(baseURL, "/")+c.path, nil)
+	if err != nil {
+		return "", err
+	}
+	if Token != "" {
+		req.Header.Set("Authorization", "Bearer "+Token)
+	}
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("Response status: %v", resp.Status)
+	}
+	return string(body), nil
+}
+
+// serverURL turns a listen address such as ":12321" into a base URL.
+// Addresses that already contain a scheme are returned unchanged.
+func serverURL(addr string) string {
+	if strings.Contains(addr, "://") {
+		return addr
+	}
+	if strings.HasPrefix(addr, ":") {
+		addr = "127.0.0.1" + addr
+	}
+	return "http://" + addr
+}
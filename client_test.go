@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	s, clock, _ := setup(t)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	for _, test := range []struct {
+		command, want string
+		d             time.Duration
+	}{
+		{"status", "25:00", 0},
+		{"start", "25:00", time.Minute},
+		{"status", "24:00", 0},
+		{"pause", "24:00", time.Minute},
+		{"resume", "24:00", 0},
+		{"skip", "05:00", 0},
+		{"toggle", "[R]", 0},
+		{"stop", "05:00", 0},
+		{"stop", "25:00", 0},
+	} {
+		out, err := clientCommand(srv.URL+"/", test.command)
+		if err != nil || out != test.want {
+			t.Errorf("%v: %q %v, want %q", test.command, out, err, test.want)
+		}
+		clock.Add(test.d)
+	}
+	if _, err := clientCommand(srv.URL, "nope"); err == nil {
+		t.Errorf("nope: no error")
+	}
+
+	// The client sends -token.
+	Token, TokenProtectReads = "secret", true
+	defer func() { Token, TokenProtectReads = "", false }()
+	if out, err := clientCommand(srv.URL, "status"); err != nil || out != "25:00" {
+		t.Errorf("with -token: %q %v", out, err)
+	}
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	if _, err := clientCommand(missing.URL, "status"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("from a server without /status: %v, want 404", err)
+	}
+	srv.Close()
+	if _, err := clientCommand(srv.URL, "status"); err == nil {
+		t.Errorf("without a server: no error")
+	}
+}
+
+func TestServerURL(t *testing.T) {
+	for addr, want := range map[string]string{
+		":12321":                 "http://127.0.0.1:12321",
+		"localhost:8080":         "http://localhost:8080",
+		"https://example.com/tm": "https://example.com/tm",
+	} {
+		if got := serverURL(addr); got != want {
+			t.Errorf("%q: %q, want %q", addr, got, want)
+		}
+	}
+}
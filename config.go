@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setFlagsFromConfig sets each flag not in fixedFlags from the JSON object
+// in filename, whose keys are the flag names, e.g.
+//
+//	{"work": "50m", "n": 3, "auto": true, "start-command": "say go"}
+//
+// A file ending in .toml is read as TOML with the same keys, e.g.
+// work = "50m", and tables such as [presets.afternoon] or [[hours]]. The
+// values go through the same validation as flags. The "presets" key
+// holds named schedules for /preset/, in the format of PUT /config:
+//
+//	{"presets": {"afternoon": {"n": 3, "durations": {"work": "50m", "short": "10m"}}}}
+//
+// The "weekdays" key replaces durations on some days:
+//
+//	{"weekdays": {"friday": {"work": "15m", "short": "3m"}}}
+//
+// the "hours" key at some times of the day:
+//
+//	{"hours": [{"until": "12:00", "durations": {"work": "25m"}}]}
+//
+// and the "profiles" key holds named sets of options for -profile:
+//
+//	{"profiles": {"writing": {"work": "50m", "command": "say done"}}}
+func setFlagsFromConfig(filename string) error {
+	config, err := readConfig(filename)
+	if err != nil {
+		return err
+	}
+	config.apply()
+	return setFlagsFrom(config.options, fixedFlags, filename)
+}
+
+// fixedFlags are the flags set on the command line, in the environment or by
+// -preset, which -config and profiles do not change.
+var fixedFlags map[string]bool
+
+// flagAliases maps flags that set the same variable as another flag to the
+// name of that flag.
+var flagAliases = map[string]string{"auto-continue": "auto"}
+
+// setFlags returns the names of the flags that have been set. Setting a flag
+// or one of its aliases counts for both.
+func setFlags() map[string]bool {
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for alias, name := range flagAliases {
+		if set[alias] || set[name] {
+			set[alias], set[name] = true, true
+		}
+	}
+	return set
+}
+
+// configFile is a parsed -config file.
+type configFile struct {
+	options  map[string]interface{} // by flag name
+	presets  map[string]scheduleConfig
+	weekdays map[time.Weekday]map[Mode]time.Duration
+	hours    []hourDurations
+	profiles map[string]map[string]interface{}
+
+	// sections are the keys among presets, weekdays, hours and profiles that
+	// the file has.
+	sections map[string]bool
+}
+
+// readConfig reads and validates filename, leaving the options to the
+// caller.
+func readConfig(filename string) (*configFile, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	config := &configFile{sections: map[string]bool{}}
+	if strings.HasSuffix(filename, ".toml") {
+		if config.options, err = parseTOML(data); err != nil {
+			return nil, fmt.Errorf("Unable to parse %v: %v", filename, err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&config.options); err != nil {
+			return nil, fmt.Errorf("Unable to parse %v: %v", filename, err)
+		}
+	}
+
+	// section moves the key name out of the options into v.
+	section := func(name string, v interface{}) (bool, error) {
+		value, ok := config.options[name]
+		if !ok {
+			return false, nil
+		}
+		delete(config.options, name)
+		config.sections[name] = true
+		data, _ := json.Marshal(value)
+		if err := json.Unmarshal(data, v); err != nil {
+			return true, fmt.Errorf("Invalid %v in %v: %v", name, filename, err)
+		}
+		return true, nil
+	}
+	if _, err := section("presets", &config.presets); err != nil {
+		return nil, err
+	}
+	var days map[string]scheduleDurations
+	if ok, err := section("weekdays", &days); err != nil {
+		return nil, err
+	} else if ok {
+		if config.weekdays, err = parseWeekdays(days); err != nil {
+			return nil, fmt.Errorf("%v in %v", err, filename)
+		}
+	}
+	if _, err := section("profiles", &config.profiles); err != nil {
+		return nil, err
+	}
+	for name, options := range config.profiles {
+		for option := range options {
+			if !reloadable(option) {
+				return nil, fmt.Errorf("Option %q can not be set by profile %q in %v", option, name, filename)
+			}
+		}
+	}
+	var windows []hoursConfig
+	if ok, err := section("hours", &windows); err != nil {
+		return nil, err
+	} else if ok {
+		if config.hours, err = parseHours(windows); err != nil {
+			return nil, fmt.Errorf("%v in %v", err, filename)
+		}
+	}
+	return config, nil
+}
+
+// apply puts the presets, weekdays, hours and profiles of config in place.
+// Presets are added to the built-in ones.
+func (config *configFile) apply() {
+	for name, preset := range config.presets {
+		Presets[name] = preset
+	}
+	if config.sections["weekdays"] {
+		WeekdayDurations = config.weekdays
+	}
+	if config.sections["hours"] {
+		HourDurations = config.hours
+	}
+	if config.sections["profiles"] {
+		Profiles = config.profiles
+	}
+}
+
+// setFlagsFrom sets the flags named by the keys of options, except those in
+// skip. source names the options in errors.
+func setFlagsFrom(options map[string]interface{}, skip map[string]bool, source string) error {
+	for name, value := range options {
+		if flag.Lookup(name) == nil || name == "config" {
+			return fmt.Errorf("Unknown option %q in %v", name, source)
+		}
+		if skip[name] {
+			continue
+		}
+		str, err := optionString(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value for %q in %v", name, source)
+		}
+		if err := flag.Set(name, str); err != nil {
+			return fmt.Errorf("Invalid value %q for %q in %v: %v", str, name, source, err)
+		}
+	}
+	return nil
+}
+
+// optionString returns a string, number or boolean option as a flag value.
+func optionString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number, float64, bool:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("Invalid value %v", value)
+}
+
+// Reload reads filename again after a SIGHUP, followed by the profile in
+// use. Options in fixedFlags are kept, and options that are only read on
+// start must not change. Nothing changes unless the whole file is valid.
+func (s *Server) Reload(filename string) {
+	if err := s.reload(filename); err != nil {
+		log.Printf("Error while reloading %v: %v", filename, err)
+	}
+}
+
+func (s *Server) reload(filename string) error {
+	config, err := readConfig(filename)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile, profiles := Profile, Profiles
+	if value, ok := config.options["profile"]; ok {
+		delete(config.options, "profile")
+		if !fixedFlags["profile"] {
+			if profile, err = optionString(value); err != nil {
+				return fmt.Errorf("Invalid value for %q in %v", "profile", filename)
+			}
+		}
+	}
+	if config.sections["profiles"] {
+		profiles = config.profiles
+	}
+	base, err := s.base.with(config.options, filename)
+	if err != nil {
+		return err
+	}
+	if err := checkProfiles(profiles, base); err != nil {
+		return fmt.Errorf("%v in %v", err, filename)
+	}
+	next := base
+	if profile != "" {
+		if next, err = profileOptions(profiles, base, profile); err != nil {
+			return err
+		}
+	}
+	st, err := next.parse()
+	if err != nil {
+		return err
+	}
+
+	config.apply()
+	Profile = profile
+	s.base = base
+	s.useSettings(st)
+	log.Printf("Reloaded %v: Interval=%v ShortBreak=%v LongBreak=%v N=%v", filename, DurationWork, DurationShortBreak, DurationLongBreak, N)
+	s.refreshStatus(true)
+	return nil
+}
+
+// runConfig runs "tomato config init [FILE]", which writes a config file
+// with every option and exits the program.
+func runConfig(args []string) {
+	if len(args) == 0 || args[0] != "init" || len(args) > 2 {
+		fatalf("Expected \"config init [FILE]\", got %q", append([]string{"config"}, args...))
+	}
+	if len(args) == 1 {
+		dir, err := configDir()
+		if err != nil {
+			fatalf("%v", err)
+		}
+		args = append(args, filepath.Join(dir, "config.toml"))
+	}
+	filename := args[1]
+	if filename == "-" {
+		fmt.Print(initConfig())
+		os.Exit(0)
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		fatalf("%v", err)
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if _, err := f.WriteString(initConfig()); err != nil {
+		fatalf("%v", err)
+	}
+	if err := f.Close(); err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Println(filename)
+	os.Exit(0)
+}
+
+// initConfig returns a TOML config file with every option and its usage.
+// Options that still have their default value are commented out.
+func initConfig() string {
+	var b strings.Builder
+	b.WriteString("# Options for tomato, see tomato -help. The command line and TOMATO_\n")
+	b.WriteString("# environment variables take precedence over this file.\n")
+	flag.VisitAll(func(f *flag.Flag) {
+		getter, ok := f.Value.(flag.Getter)
+		if !ok || f.Name == "config" || f.Name == "self-test" || flagAliases[f.Name] != "" {
+			return
+		}
+		value := f.Value.String()
+		switch getter.Get().(type) {
+		case string:
+			value = strconv.Quote(value)
+		}
+		prefix := ""
+		if f.Value.String() == f.DefValue {
+			prefix = "# "
+		}
+		fmt.Fprintf(&b, "\n# %v\n%v%v = %v\n", strings.Replace(f.Usage, "\n", "\n# ", -1), prefix, f.Name, value)
+	})
+	return b.String()
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	s, _, _ := setup(t)
+	fixedFlags = map[string]bool{"long": true}
+	filename := writeFile(t, "config.json", `{"work": "50m", "long": "40m", "n": 3, "tick": 200, "command": "say done", "listen": ":12321"}`)
+
+	s.Reload(filename)
+	if DurationWork != 50*time.Minute || N != 3 || Command != "say done" || Tick != 200*time.Millisecond {
+		t.Errorf("work=%v n=%v command=%q tick=%v", DurationWork, N, Command, Tick)
+	}
+	if DurationLongBreak != 15*time.Minute {
+		t.Errorf("long=%v, want the fixed 15m", DurationLongBreak)
+	}
+	select {
+	case d := <-s.retick:
+		if d != 200*time.Millisecond {
+			t.Errorf("retick=%v", d)
+		}
+	default:
+		t.Errorf("the ticker was not told about the new tick")
+	}
+}
+
+func TestReloadRejects(t *testing.T) {
+	for _, config := range []string{
+		`{"work": "50m", "listen": ":8080"}`,
+		`{"work": "50m", "uuid": "x"}`,
+		`{"work": "50m", "short": "abc"}`,
+		`{"work": "50m", "format": "{bad}"}`,
+		`{"work": "50m", "tick": 5}`,
+		`{"work": "50m", "min-break": "200%"}`,
+		`{"work": "50m", "nope": 1}`,
+		`{"work": "50m", "profiles": {"a": {"listen": ":8080"}}}`,
+		`{"work": "50m", "profiles": {"a": {"short": "abc"}}}`,
+		`{"work": "50m", "profile": "missing"}`,
+		`{"work": "50m"`,
+	} {
+		s, _, _ := setup(t)
+		s.Reload(writeFile(t, "config.json", config))
+		if DurationWork != 25*time.Minute || s.base["work"] != "25m" || Profiles != nil {
+			t.Errorf("%v: work=%v base=%v profiles=%v, want nothing changed", config, DurationWork, s.base["work"], Profiles)
+		}
+	}
+}
+
+func TestReloadProfile(t *testing.T) {
+	s, _, _ := setup(t)
+	filename := writeFile(t, "config.json", `{"short": "7m", "profiles": {"writing": {"work": "50m", "command": "say done"}, "reading": {"short": "10m"}}}`)
+	s.Reload(filename)
+
+	if rec := do(s.Handler(), "POST", "/profile/writing", nil); rec.Code != http.StatusOK {
+		t.Fatalf("POST /profile/writing: %v %v", rec.Code, rec.Body)
+	}
+	if DurationWork != 50*time.Minute || DurationShortBreak != 7*time.Minute || Command != "say done" {
+		t.Errorf("writing: work=%v short=%v command=%q", DurationWork, DurationShortBreak, Command)
+	}
+	if rec := do(s.Handler(), "POST", "/profile/reading", nil); rec.Code != http.StatusOK {
+		t.Fatalf("POST /profile/reading: %v %v", rec.Code, rec.Body)
+	}
+	if DurationWork != 25*time.Minute || DurationShortBreak != 10*time.Minute || Command != "" {
+		t.Errorf("reading: work=%v short=%v command=%q, want the options of writing undone", DurationWork, DurationShortBreak, Command)
+	}
+
+	// The profile in use applies again on top of the reloaded file.
+	s.Reload(filename)
+	if Profile != "reading" || DurationShortBreak != 10*time.Minute {
+		t.Errorf("after reload: profile=%q short=%v", Profile, DurationShortBreak)
+	}
+	if rec := do(s.Handler(), "POST", "/profile/missing", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("POST /profile/missing: %v", rec.Code)
+	}
+}
+
+// TestReloadRace reloads the config and switches profiles while actions and
+// updates to BetterTouchTool run. Run it with -race.
+func TestReloadRace(t *testing.T) {
+	var requests int32
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer btt.Close()
+
+	s, _, _ := setup(t)
+	URL, UUID, HTTPRetries = btt.URL, "uuid", 1
+	defer func() { URL, UUID, HTTPRetries = "", "", 0 }()
+	icons, err := loadIcons(currentOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	icons.apply()
+	h := s.Handler()
+
+	files := []string{
+		writeFile(t, "a.json", `{"work": "50m", "n": 3, "tick": 200, "format": "{timer} {count}", "profiles": {"p": {"short": "9m"}}}`),
+		writeFile(t, "b.json", `{"work": "30m", "n": 2, "tick": 100, "command": "say done", "profiles": {"p": {"short": "4m"}}}`),
+	}
+	s.Reload(files[0])
+
+	var wg sync.WaitGroup
+	run := func(f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				f(i)
+			}
+		}()
+	}
+	run(func(i int) { s.Reload(files[i%2]) })
+	run(func(i int) { do(h, "POST", "/profile/p", nil) })
+	run(func(i int) { do(h, "POST", "/action/toggle", nil) })
+	run(func(i int) { do(h, "GET", "/config", nil) })
+	run(func(i int) { do(h, "PUT", "/config", strings.NewReader(fmt.Sprintf(`{"n": %v}`, i%5))) })
+	run(func(i int) { s.RefreshStatus(true) })
+	wg.Wait()
+	s.requests.Wait()
+
+	if atomic.LoadInt32(&requests) == 0 {
+		t.Errorf("no updates sent to BetterTouchTool")
+	}
+}
+
+// TestPrecedence checks that the command line wins over -preset, which wins
+// over the config file, on start and on SIGHUP.
+func TestPrecedence(t *testing.T) {
+	s, _, _ := setup(t)
+	fixedFlags = map[string]bool{"short": true} // as if given on the command line
+	filename := writeFile(t, "config.json", `{"work": "50m", "short": "7m", "long": "40m", "n": 2, "warn": "1m"}`)
+
+	if err := setFlagsFromConfig(filename); err != nil {
+		t.Fatal(err)
+	}
+	if err := setFlagsFromPreset("52-17"); err != nil {
+		t.Fatal(err)
+	}
+	st, err := currentOptions().parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.apply()
+	s.base = currentOptions()
+	check := func(when string) {
+		t.Helper()
+		if DurationWork != 52*time.Minute || DurationShortBreak != 5*time.Minute || DurationLongBreak != 17*time.Minute || N != 4 || WarnBefore != time.Minute {
+			t.Errorf("%v: work=%v short=%v long=%v n=%v warn=%v", when, DurationWork, DurationShortBreak, DurationLongBreak, N, WarnBefore)
+		}
+	}
+	check("on start")
+	s.Reload(filename)
+	check("after SIGHUP")
+}
+
+// TestConfigFile loads the same options from JSON and TOML, with -work and
+// -uuid given on the command line.
+func TestConfigFile(t *testing.T) {
+	for name, config := range map[string]string{
+		"config.json": `{"work": "50", "short": "7m", "n": 3, "auto": true, "command": "say done",
+			"icon1": "/tmp/red.png", "listen": ":8080", "uuid": "from-file", "port": "12345"}`,
+		"config.toml": `work = "50"
+short = "7m"
+n = 3
+auto = true
+command = "say done"
+icon1 = "/tmp/red.png"
+listen = ":8080"
+uuid = "from-file"
+port = "12345"`,
+	} {
+		setup(t)
+		// As if given on the command line.
+		flag.Set("work", "45m")
+		flag.Set("uuid", "from-flag")
+		fixedFlags = map[string]bool{"work": true, "uuid": true}
+
+		if err := setFlagsFromConfig(writeFile(t, name, config)); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		st, err := currentOptions().parse()
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		st.apply()
+		if DurationWork != 45*time.Minute || DurationShortBreak != 7*time.Minute || DurationLongBreak != 15*time.Minute || N != 3 {
+			t.Errorf("%v: work=%v short=%v long=%v n=%v", name, DurationWork, DurationShortBreak, DurationLongBreak, N)
+		}
+		if !AutoAdvance || Command != "say done" || Icon1 != "/tmp/red.png" || *flListen != ":8080" || *flPort != "12345" {
+			t.Errorf("%v: auto=%v command=%q icon1=%q listen=%q port=%q", name, AutoAdvance, Command, Icon1, *flListen, *flPort)
+		}
+		if UUID != "from-flag" {
+			t.Errorf("%v: uuid=%q, want the one from the command line", name, UUID)
+		}
+	}
+}
+
+// TestConfigFileRejects checks that a file goes through the validation of
+// the flags.
+func TestConfigFileRejects(t *testing.T) {
+	for _, config := range []string{
+		`{"work": "abc"}`,
+		`{"work": "-5m"}`,
+		`{"n": -1}`,
+		`{"n": "three"}`,
+		`{"tick": 5}`,
+		`{"auto": "maybe"}`,
+		`{"format": "{bad}"}`,
+		`{"nope": 1}`,
+		`{"config": "other.json"}`,
+		`{"n": [3]}`,
+		`{"work": "50m"`,
+	} {
+		setup(t)
+		err := setFlagsFromConfig(writeFile(t, "config.json", config))
+		if err == nil {
+			_, err = currentOptions().parse()
+		}
+		if err == nil {
+			t.Errorf("%v: no error", config)
+		}
+	}
+}
+
+// TestConfigPreset checks the order of the command line, -preset and the
+// file for a preset from the "presets" of the file.
+func TestConfigPreset(t *testing.T) {
+	s, _, _ := setup(t)
+	presets := Presets
+	Presets = map[string]scheduleConfig{}
+	for name, preset := range presets {
+		Presets[name] = preset
+	}
+	defer func() { Presets = presets }()
+	flag.Set("short", "3m")
+	fixedFlags = map[string]bool{"short": true} // as if given on the command line
+	filename := writeFile(t, "config.json", `{"work": "50m", "short": "7m", "long": "40m",
+		"presets": {"deep": {"n": 2, "durations": {"work": "90m", "short": "20m"}}}}`)
+
+	if err := setFlagsFromConfig(filename); err != nil {
+		t.Fatal(err)
+	}
+	if err := setFlagsFromPreset("deep"); err != nil {
+		t.Fatal(err)
+	}
+	st, err := currentOptions().parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.apply()
+	s.base = currentOptions()
+	check := func(when string) {
+		t.Helper()
+		if DurationWork != 90*time.Minute || DurationShortBreak != 3*time.Minute || DurationLongBreak != 40*time.Minute || N != 2 {
+			t.Errorf("%v: work=%v short=%v long=%v n=%v, want the preset over the file under the command line", when, DurationWork, DurationShortBreak, DurationLongBreak, N)
+		}
+	}
+	check("on start")
+	s.Reload(filename)
+	check("after SIGHUP")
+}
+
+// TestFlagAlias checks that -auto and -auto-continue count as one flag for
+// the precedence of the command line, the environment and the file.
+func TestFlagAlias(t *testing.T) {
+	for _, given := range []string{"auto", "auto-continue"} {
+		setup(t)
+		flag.Set(given, "true")
+		set := setFlags()
+		if !set["auto"] || !set["auto-continue"] {
+			t.Errorf("-%v: set=%v, want both names", given, set)
+		}
+		t.Setenv("TOMATO_AUTO_CONTINUE", "false")
+		t.Setenv("TOMATO_AUTO", "false")
+		if err := setFlagsFromEnv(set); err != nil {
+			t.Fatal(err)
+		}
+		fixedFlags = set
+		if err := setFlagsFromConfig(writeFile(t, "config.json", `{"auto": false, "auto-continue": false}`)); err != nil {
+			t.Fatal(err)
+		}
+		if !AutoAdvance {
+			t.Errorf("-%v: the environment or the file turned it off", given)
+		}
+	}
+
+	// In the environment, the first name wins.
+	setup(t)
+	t.Setenv("TOMATO_AUTO", "true")
+	t.Setenv("TOMATO_AUTO_CONTINUE", "false")
+	if err := setFlagsFromEnv(nil); err != nil || !AutoAdvance {
+		t.Errorf("TOMATO_AUTO=true TOMATO_AUTO_CONTINUE=false: auto=%v %v", AutoAdvance, err)
+	}
+
+	setup(t)
+	flag.Set("auto", "true")
+	config := initConfig()
+	if !strings.Contains(config, "\nauto = true\n") || strings.Contains(config, "auto-continue") {
+		t.Errorf("config init writes:\n%v", config)
+	}
+}
+
+// TestSetDataFiles checks that the state and history go to the data
+// directory unless they are set or the history is kept in memory.
+func TestSetDataFiles(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("the data directory is the config directory")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	for _, test := range []struct {
+		set            map[string]bool
+		storage        string
+		state, history string
+	}{
+		{nil, "", "state.json", "history.jsonl"},
+		{nil, "sqlite", "state.json", "history.db"},
+		{nil, "memory", "state.json", ""},
+		{map[string]bool{"state": true}, "", "", "history.jsonl"},
+		{map[string]bool{"state": true, "history": true}, "", "", ""},
+	} {
+		StateFile, HistoryFile, StorageBackend = "", "", test.storage
+		if err := setDataFiles(test.set); err != nil {
+			t.Fatal(err)
+		}
+		want := func(name string) string {
+			if name == "" {
+				return ""
+			}
+			return filepath.Join(dir, "tomato", name)
+		}
+		if StateFile != want(test.state) || HistoryFile != want(test.history) {
+			t.Errorf("%+v: state=%q history=%q", test, StateFile, HistoryFile)
+		}
+	}
+	StateFile, HistoryFile, StorageBackend = "", "", ""
+	if _, err := os.Stat(filepath.Join(dir, "tomato")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cycleStep is one interval of a -cycle.
+type cycleStep struct {
+	mode Mode
+	d    time.Duration
+}
+
+// Cycle is the sequence of intervals given by -cycle. When it is empty, the
+// timer alternates work and short breaks with a long break every N work
+// intervals.
+var Cycle []cycleStep
+
+// parseCycle parses a comma separated list of intervals such as
+// "25m work,5m break,25m work,15m long-break". "break" is a short break.
+func parseCycle(spec string) ([]cycleStep, error) {
+	var steps []cycleStep
+	works := 0
+	for _, item := range strings.Split(spec, ",") {
+		fields := strings.Fields(item)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("Invalid interval %q in cycle, expected e.g. \"25m work\"", strings.TrimSpace(item))
+		}
+		d, err := parseDuration(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		mode := Mode(fields[1])
+		if mode == "break" {
+			mode = ModeShortBreak
+		}
+		if !mode.Valid() {
+			return nil, fmt.Errorf("Invalid mode %q in cycle", fields[1])
+		}
+		if mode == ModeWork {
+			works++
+		} else if len(steps) > 0 && steps[len(steps)-1].mode == mode {
+			// The position in the cycle is told apart by the mode and
+			// the work intervals before it.
+			return nil, fmt.Errorf("Two %v intervals in a row in cycle", mode)
+		}
+		steps = append(steps, cycleStep{mode, d})
+	}
+	if works == 0 {
+		return nil, fmt.Errorf("No work interval in cycle")
+	}
+	return steps, nil
+}
+
+// cycleWorks returns the number of work intervals in Cycle.
+func cycleWorks() int {
+	n := 0
+	for _, step := range Cycle {
+		if step.mode == ModeWork {
+			n++
+		}
+	}
+	return n
+}
+
+// inCycle reports whether Cycle has an interval of mode. Without -cycle,
+// every mode is in it.
+func inCycle(mode Mode) bool {
+	if len(Cycle) == 0 {
+		return true
+	}
+	for _, step := range Cycle {
+		if step.mode == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// notInCycle returns the error for a mode that inCycle rejects.
+func notInCycle(mode Mode) string {
+	return fmt.Sprintf("Mode %q is not in the cycle (%v)", mode, formatCycle())
+}
+
+// cycleIndex returns the position in Cycle of an interval of mode after
+// works work intervals. If there is no such interval, e.g. after
+// /action/cycle, it returns the first interval of mode.
+func cycleIndex(works int, mode Mode) int {
+	first := -1
+	done := 0
+	for i, step := range Cycle {
+		if step.mode == mode {
+			if done == works {
+				return i
+			}
+			if first < 0 {
+				first = i
+			}
+		}
+		if step.mode == ModeWork {
+			done++
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+	return first
+}
+
+// nextStep advances to the next interval of Cycle. The count starts over
+// when the cycle does.
+func (s *Server) nextStep() {
+	i := (cycleIndex(s.workSinceLongBreak, s.mode) + 1) % len(Cycle)
+	if s.mode == ModeWork {
+		s.count++
+		s.workSinceLongBreak++
+	}
+	if i == 0 {
+		s.count = 0
+		s.workSinceLongBreak = 0
+	}
+	s.mode = Cycle[i].mode
+}
+
+// formatCycle returns Cycle in the form accepted by -cycle.
+func formatCycle() string {
+	items := make([]string, len(Cycle))
+	for i, step := range Cycle {
+		items[i] = fmt.Sprintf("%v %v", step.d, step.mode)
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WeekdayDurations replace the durations of -work, -short and -long on some
+// days of the week. They are read from the "weekdays" key of -config.
+var WeekdayDurations map[time.Weekday]map[Mode]time.Duration
+
+// HourDurations replace the durations of -work, -short and -long, and those
+// of WeekdayDurations, at some times of the day. They are read from the
+// "hours" key of -config. The first matching window wins.
+var HourDurations []hourDurations
+
+// hoursConfig is a time window in the "hours" key of -config, e.g.
+// {"from": "14:00", "durations": {"work": "45m"}}. From defaults to the
+// start of the day, and until to its end.
+type hoursConfig struct {
+	From      string            `json:"from"`
+	Until     string            `json:"until"`
+	Durations scheduleDurations `json:"durations"`
+}
+
+// hourDurations are the durations between from and until, as times of the
+// day. A window with until before from spans midnight.
+type hourDurations struct {
+	from, until time.Duration
+	durations   map[Mode]time.Duration
+}
+
+func (h hourDurations) contains(t time.Time) bool {
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if h.from <= h.until {
+		return h.from <= clock && clock < h.until
+	}
+	return clock >= h.from || clock < h.until
+}
+
+// parseHours parses the "hours" key of -config.
+func parseHours(windows []hoursConfig) ([]hourDurations, error) {
+	var out []hourDurations
+	for _, w := range windows {
+		h := hourDurations{until: 24 * time.Hour}
+		var err error
+		if w.From != "" {
+			if h.from, err = parseClock(w.From); err != nil {
+				return nil, err
+			}
+		}
+		if w.Until != "" {
+			if h.until, err = parseClock(w.Until); err != nil {
+				return nil, err
+			}
+		}
+		if h.durations, err = w.Durations.parse(); err != nil {
+			return nil, fmt.Errorf("Invalid durations from %q until %q: %v", w.From, w.Until, err)
+		}
+		out = append(out, h)
+	}
+	return out, nil
+}
+
+// parseClock parses a time of the day given as "15:04" or "15:04:05" and
+// returns it as the time since midnight.
+func parseClock(str string) (time.Duration, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, str); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("Invalid time %q, expected e.g. 14:30", str)
+}
+
+// parseWeekdays parses the durations of each day, keyed by the English name
+// of the day, e.g. {"friday": {"work": "15m"}}.
+func parseWeekdays(days map[string]scheduleDurations) (map[time.Weekday]map[Mode]time.Duration, error) {
+	out := map[time.Weekday]map[Mode]time.Duration{}
+	for name, durations := range days {
+		day, ok := parseWeekday(name)
+		if !ok {
+			return nil, fmt.Errorf("Invalid weekday %q", name)
+		}
+		modes, err := durations.parse()
+		if err != nil {
+			return nil, fmt.Errorf("Invalid durations for %v: %v", name, err)
+		}
+		out[day] = modes
+	}
+	return out, nil
+}
+
+// parseWeekday parses the English name of a day, e.g. "friday" or "fri".
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) || strings.EqualFold(name, day.String()[:3]) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// parse returns the durations that are set, by mode.
+func (d scheduleDurations) parse() (map[Mode]time.Duration, error) {
+	out := map[Mode]time.Duration{}
+	for mode, str := range map[Mode]string{
+		ModeWork:       d.Work,
+		ModeShortBreak: d.ShortBreak,
+		ModeLongBreak:  d.LongBreak,
+	} {
+		if str == "" {
+			continue
+		}
+		v, err := parseDuration(str)
+		if err != nil {
+			return nil, err
+		}
+		out[mode] = v
+	}
+	return out, nil
+}
+
+// durationAt returns the duration of mode configured for t, if any
+// overrides the flags.
+func durationAt(mode Mode, t time.Time) (time.Duration, bool) {
+	for _, h := range HourDurations {
+		if d, ok := h.durations[mode]; ok && h.contains(t) {
+			return d, true
+		}
+	}
+	d, ok := WeekdayDurations[t.Weekday()][mode]
+	return d, ok
+}
@@ -0,0 +1,36 @@
+package main
+
+import "time"
+
+var (
+	// EyeCommand runs after every EyeEvery of running work time, e.g. to
+	// look away from the screen for 20 seconds (the 20-20-20 rule).
+	EyeCommand string
+	EyeEvery   time.Duration
+)
+
+// checkEyes runs EyeCommand when another EyeEvery of work has passed since
+// the last run or break. The timer keeps running.
+func (s *Server) checkEyes() {
+	if EyeCommand == "" || s.mode != ModeWork || s.state != StateRunning {
+		return
+	}
+	worked := s.eyeWork + s.elapsed()
+	if worked-s.eyeRun < EyeEvery {
+		return
+	}
+	s.eyeRun = worked
+	if !quiet() {
+		s.runCommand(EyeCommand)
+	}
+}
+
+// eyesIntervalEnded keeps the work time of an ended work interval for
+// checkEyes. A break starts the count over.
+func (s *Server) eyesIntervalEnded() {
+	if s.mode == ModeWork {
+		s.eyeWork += s.elapsed()
+	} else {
+		s.eyeWork, s.eyeRun = 0, 0
+	}
+}
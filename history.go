@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// historySize bounds the number of intervals kept in memory.
+const historySize = 1000
+
+const (
+	OutcomeCompleted = "completed"
+	OutcomeStopped   = "stopped"
+	OutcomeSkipped   = "skipped"
+)
+
+// HistoryFile is the JSON Lines file of the jsonl storage.
+var HistoryFile string
+
+// historyRecord describes one interval that has ended.
+type historyRecord struct {
+	Mode    Mode      `json:"mode"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Outcome string    `json:"outcome"`
+
+	// Planned is the length in seconds scheduled for the interval, and
+	// Paused the time in seconds it was paused. Neither is set for a
+	// stopwatch.
+	Planned int `json:"planned,omitempty"`
+	Paused  int `json:"paused,omitempty"`
+
+	// Overtime is the time in seconds a work interval ran past its end,
+	// see -overtime.
+	Overtime int `json:"overtime,omitempty"`
+}
+
+// history is a ring buffer of the last historySize records.
+type history struct {
+	records [historySize]historyRecord
+	next    int // index of the next record to write
+	len     int
+}
+
+func (h *history) add(rec historyRecord) {
+	h.records[h.next] = rec
+	h.next = (h.next + 1) % historySize
+	if h.len < historySize {
+		h.len++
+	}
+}
+
+// recent returns up to limit records, oldest first.
+func (h *history) recent(limit int) []historyRecord {
+	if limit > h.len {
+		limit = h.len
+	}
+	out := make([]historyRecord, limit)
+	for i := range out {
+		out[i] = h.records[(h.next-limit+i+historySize)%historySize]
+	}
+	return out
+}
+
+// endInterval records the end of the current interval with the given
+// outcome. The next interval is assumed to start at end.
+func (s *Server) endInterval(outcome string, end time.Time) {
+	start := s.began
+	if start.IsZero() {
+		start = end.Add(-s.duration())
+	}
+	s.eyesIntervalEnded()
+	rec := historyRecord{Mode: s.mode, Start: start, End: end, Outcome: outcome}
+	if !s.stopwatch() {
+		paused := s.paused
+		if s.state == StatePaused && !s.pausedAt.IsZero() {
+			paused += end.Sub(s.pausedAt)
+		}
+		rec.Planned = int(s.duration() / time.Second)
+		rec.Paused = int(paused / time.Second)
+	}
+	if s.overtime {
+		rec.Overtime = int(end.Sub(s.t) / time.Second)
+		s.overtime = false
+	}
+	s.began = end
+	s.once, s.planned, s.paused, s.pausedAt = 0, 0, 0, time.Time{}
+	s.warned = false
+
+	if err := s.storage.SaveSession(rec); err != nil {
+		log.Printf("Error while writing history: %v", err)
+	}
+}
+
+// History reports the most recent intervals, oldest first. The limit
+// parameter sets how many (default 100, 0 for all), and offset how many of the most
+// recent ones to skip. from and to, as a date such as 2024-05-02 or an
+// RFC 3339 time, bound the start of the intervals, and mode keeps only
+// intervals of that mode.
+func (s *Server) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+
+	filter, err := parseSessionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshStatus(false)
+	records, err := s.storage.ListSessions(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, _ := json.Marshal(records)
+	w.Write(data)
+}
+
+// HistoryStats sums up the intervals by mode, with the from, to and mode
+// parameters of History.
+func (s *Server) HistoryStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+	filter, err := parseSessionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshStatus(false)
+	stats, err := s.storage.Stats(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, _ := json.Marshal(stats)
+	w.Write(data)
+}
+
+// parseSessionFilter reads the parameters of History.
+func parseSessionFilter(r *http.Request) (sessionFilter, error) {
+	filter := sessionFilter{Limit: 100, Mode: Mode(r.FormValue("mode"))}
+	for name, n := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if str := r.FormValue(name); str != "" {
+			v, err := strconv.Atoi(str)
+			if err != nil || v < 0 {
+				return filter, fmt.Errorf("Invalid %v", name)
+			}
+			*n = v
+		}
+	}
+	if filter.Mode != "" && !filter.Mode.Valid() {
+		return filter, fmt.Errorf("Invalid mode %q", filter.Mode)
+	}
+	var err error
+	if filter.From, err = parseHistoryTime(r.FormValue("from"), false); err != nil {
+		return filter, fmt.Errorf("Invalid from: %v", err)
+	}
+	if filter.To, err = parseHistoryTime(r.FormValue("to"), true); err != nil {
+		return filter, fmt.Errorf("Invalid to: %v", err)
+	}
+	return filter, nil
+}
+
+// parseHistoryTime parses an RFC 3339 time, or a local date such as
+// 2024-05-02. A date stands for its start, or with end for the start of the
+// next day, so that to=2024-05-02 includes that day.
+func parseHistoryTime(str string, end bool) (time.Time, error) {
+	if str == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", str, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a date such as 2006-01-02 or an RFC 3339 time, got %q", str)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
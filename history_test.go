@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSessionFilter(t *testing.T) {
+	day := time.Date(2024, time.May, 2, 0, 0, 0, 0, time.Local)
+	for _, tt := range []struct {
+		query string
+		want  sessionFilter
+		err   bool
+	}{
+		{"", sessionFilter{Limit: 100}, false},
+		{"limit=0", sessionFilter{}, false},
+		{"limit=5&offset=10", sessionFilter{Limit: 5, Offset: 10}, false},
+		{"mode=work", sessionFilter{Limit: 100, Mode: ModeWork}, false},
+		{"from=2024-05-02&to=2024-05-02", sessionFilter{Limit: 100, From: day, To: day.AddDate(0, 0, 1)}, false},
+		{"from=2024-05-02T10:00:00Z", sessionFilter{Limit: 100, From: time.Date(2024, time.May, 2, 10, 0, 0, 0, time.UTC)}, false},
+		{"limit=-1", sessionFilter{}, true},
+		{"limit=x", sessionFilter{}, true},
+		{"offset=-3", sessionFilter{}, true},
+		{"mode=nap", sessionFilter{}, true},
+		{"from=yesterday", sessionFilter{}, true},
+		{"to=2024-13-01", sessionFilter{}, true},
+	} {
+		got, err := parseSessionFilter(httptest.NewRequest("GET", "/history?"+tt.query, nil))
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: no error", tt.query)
+			}
+			continue
+		}
+		if err != nil || !got.From.Equal(tt.want.From) || !got.To.Equal(tt.want.To) {
+			t.Errorf("%q: %+v, %v, want %+v", tt.query, got, err, tt.want)
+		}
+		got.From, got.To, tt.want.From, tt.want.To = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+		if got != tt.want {
+			t.Errorf("%q: %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSessionFilterApply(t *testing.T) {
+	records := testRecords()
+	for _, tt := range []struct {
+		filter sessionFilter
+		want   []int // indexes into records
+	}{
+		{sessionFilter{}, []int{0, 1, 2}},
+		{sessionFilter{Limit: 2}, []int{1, 2}},
+		{sessionFilter{Limit: 2, Offset: 1}, []int{0, 1}},
+		{sessionFilter{Offset: 5}, []int{}},
+		{sessionFilter{Mode: ModeWork}, []int{0, 2}},
+		{sessionFilter{Mode: ModeWork, Limit: 1}, []int{2}},
+		{sessionFilter{From: records[1].Start}, []int{1, 2}},
+		{sessionFilter{To: records[1].Start}, []int{0}},
+	} {
+		want := []historyRecord{}
+		for _, i := range tt.want {
+			want = append(want, records[i])
+		}
+		if got := tt.filter.apply(records); !reflect.DeepEqual(got, want) {
+			t.Errorf("%+v: %v records, want %v", tt.filter, len(got), tt.want)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(25*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	do(h, "POST", "/action/start", nil)
+	clock.Add(time.Minute)
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/start", nil)
+	clock.Add(10 * time.Minute)
+	do(h, "POST", "/action/stop", nil)
+
+	list := func(query string) []historyRecord {
+		t.Helper()
+		rec := do(h, "GET", "/history"+query, nil)
+		var records []historyRecord
+		if err := json.Unmarshal(rec.Body.Bytes(), &records); rec.Code != http.StatusOK || err != nil {
+			t.Fatalf("GET /history%v: %v %v", query, rec.Code, rec.Body)
+		}
+		return records
+	}
+	records := list("")
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.Local)
+	want := []historyRecord{
+		{Mode: ModeWork, Start: start, End: start.Add(25 * time.Minute), Outcome: OutcomeCompleted, Planned: 1500},
+		{Mode: ModeShortBreak, Start: start.Add(25*time.Minute + time.Second), End: start.Add(26*time.Minute + time.Second), Outcome: OutcomeSkipped, Planned: 300},
+		{Mode: ModeWork, Start: start.Add(26*time.Minute + time.Second), End: start.Add(36*time.Minute + time.Second), Outcome: OutcomeStopped, Planned: 1500},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("records=%+v", records)
+	}
+	for i := range want {
+		if records[i].Mode != want[i].Mode || !records[i].Start.Equal(want[i].Start) || !records[i].End.Equal(want[i].End) || records[i].Outcome != want[i].Outcome || records[i].Planned != want[i].Planned {
+			t.Errorf("record %v: %+v, want %+v", i, records[i], want[i])
+		}
+	}
+	if records := list("?limit=2"); len(records) != 2 || records[0].Mode != ModeShortBreak || records[1].Outcome != OutcomeStopped {
+		t.Errorf("?limit=2: %+v, want the last two", records)
+	}
+	if rec := do(h, "GET", "/history?limit=x", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("?limit=x: %v", rec.Code)
+	}
+}
+
+func TestHistoryBound(t *testing.T) {
+	var h history
+	for i := 0; i < historySize+5; i++ {
+		h.SaveSession(historyRecord{Planned: i})
+	}
+	records, _ := h.ListSessions(sessionFilter{})
+	if len(records) != historySize || records[0].Planned != 5 || records[historySize-1].Planned != historySize+4 {
+		t.Errorf("%v records from %v to %v, want the last %v", len(records), records[0].Planned, records[len(records)-1].Planned, historySize)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// metrics are the counters reported by /metrics. They are guarded by
+// Server.mu.
+type metrics struct {
+	workCompleted   int64
+	breaksCompleted int64
+	commands        int64
+	commandFailures int64
+	requestFailures int64
+}
+
+// Metrics reports the counters and the current timer in the Prometheus text
+// format.
+func (s *Server) Metrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshStatus(false)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, m := range []struct {
+		name, help string
+		value      int64
+	}{
+		{"tomato_work_intervals_completed_total", "Work intervals that ran to completion.", s.metrics.workCompleted},
+		{"tomato_breaks_completed_total", "Breaks that ran to completion.", s.metrics.breaksCompleted},
+		{"tomato_commands_total", "Commands executed.", s.metrics.commands},
+		{"tomato_command_failures_total", "Commands that failed.", s.metrics.commandFailures},
+		{"tomato_request_failures_total", "Failed updates to BetterTouchTool.", s.metrics.requestFailures},
+	} {
+		fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v counter\n%v %v\n", m.name, m.help, m.name, m.name, m.value)
+	}
+	fmt.Fprintf(w, "# HELP tomato_remaining_seconds Time left in the current interval.\n")
+	fmt.Fprintf(w, "# TYPE tomato_remaining_seconds gauge\n")
+	fmt.Fprintf(w, "tomato_remaining_seconds{mode=%q} %v\n", s.mode, int(s.remaining()/time.Second))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer btt.Close()
+
+	s, clock, _ := setup(t)
+	execCommand = exec.Command // run them for real
+	Command = "exit 1"
+	h := s.Handler()
+	// metrics returns the samples of GET /metrics by name and labels.
+	metrics := func() map[string]string {
+		t.Helper()
+		rec := do(h, "GET", "/metrics", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /metrics: %v %v", rec.Code, rec.Body)
+		}
+		samples := map[string]string{}
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if i := strings.LastIndex(line, " "); i > 0 && !strings.HasPrefix(line, "#") {
+				samples[line[:i]] = line[i+1:]
+			}
+		}
+		return samples
+	}
+	check := func(when string, want map[string]string) {
+		t.Helper()
+		s.RefreshStatus(false) // end the interval and wait for its command
+		s.running.Wait()
+		s.requests.Wait()
+		got := metrics()
+		for name, value := range want {
+			if got[name] != value {
+				t.Errorf("%v: %v=%q, want %q", when, name, got[name], value)
+			}
+		}
+	}
+
+	check("on start", map[string]string{
+		"tomato_work_intervals_completed_total": "0",
+		"tomato_breaks_completed_total":         "0",
+		"tomato_commands_total":                 "0",
+		"tomato_command_failures_total":         "0",
+		"tomato_request_failures_total":         "0",
+		`tomato_remaining_seconds{mode="work"}`: "1500",
+	})
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(25*time.Minute + time.Second)
+	check("after a work interval", map[string]string{
+		"tomato_work_intervals_completed_total":        "1",
+		"tomato_breaks_completed_total":                "0",
+		"tomato_commands_total":                        "1",
+		"tomato_command_failures_total":                "1",
+		`tomato_remaining_seconds{mode="short-break"}`: "300",
+	})
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(5*time.Minute + time.Second)
+	check("after a break", map[string]string{
+		"tomato_work_intervals_completed_total": "1",
+		"tomato_breaks_completed_total":         "1",
+		"tomato_commands_total":                 "2",
+		"tomato_command_failures_total":         "2",
+		`tomato_remaining_seconds{mode="work"}`: "1500",
+	})
+
+	URL = btt.URL
+	defer func() {
+		s.requests.Wait()
+		URL = ""
+	}()
+	forgetLastRequest()
+	s.RefreshStatus(true)
+	check("after BetterTouchTool failed", map[string]string{
+		"tomato_request_failures_total": "1",
+	})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// richNotify shows a macOS notification with buttons to start the next
+// interval or skip it. It uses alerter (https://github.com/vjeantet/alerter)
+// and falls back to a plain notification when alerter is not installed.
+// It blocks until the notification is dismissed, so call it in a goroutine.
+// The buttons only act while the timer is still in state, the label of the
+// state when the notification was shown.
+func (s *Server) richNotify(finished, next Mode, state string) {
+	title := "Tomato"
+	message := fmt.Sprintf("Time is over (%v)", finished)
+	if DryRun {
+		log.Printf("Dry run, not showing notification: %q", message)
+		return
+	}
+	start := fmt.Sprintf("Start %v", next)
+	if _, err := exec.LookPath("alerter"); err != nil {
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		if err := execCommand("osascript", "-e", script).Run(); err != nil {
+			log.Printf("Error while showing notification: %v", err)
+		}
+		return
+	}
+
+	out, err := execCommand("alerter",
+		"-title", title,
+		"-message", message,
+		"-actions", start+",Skip",
+		"-closeLabel", "Dismiss",
+		"-timeout", "300",
+	).Output()
+	if err != nil {
+		log.Printf("Error while showing notification: %v", err)
+		return
+	}
+	// A click may come long after the timer moved on.
+	guard := "?if=" + url.QueryEscape(state)
+	switch strings.TrimSpace(string(out)) {
+	case start, "@CONTENTCLICKED":
+		s.action("/action/start" + guard)
+	case "Skip":
+		s.action("/action/skip" + guard)
+	}
+}
+
+// action performs a POST to one of the server's own endpoints and returns
+// the response status and body. It does not need the token.
+func (s *Server) action(path string) (int, string) {
+	rec := httptest.NewRecorder()
+	s.mux().ServeHTTP(rec, httptest.NewRequest("POST", path, nil))
+	return rec.Code, rec.Body.String()
+}
+
+// notify shows a desktop notification that finished is over and next is
+// up. It logs the message instead when no notification tool is available.
+func notify(finished, next Mode) {
+	title := "Tomato"
+	message := fmt.Sprintf("Time is over (%v), next: %v", finished, next)
+	if DryRun {
+		log.Printf("Dry run, not showing notification: %q", message)
+		return
+	}
+	name, args := notifyCommand(runtime.GOOS, title, message)
+	if name != "" {
+		if _, err := exec.LookPath(name); err == nil {
+			if err := execCommand(name, args...).Run(); err != nil {
+				log.Printf("Error while showing notification: %v", err)
+			}
+			return
+		}
+	}
+	log.Printf("%v: %v", title, message)
+}
+
+// notifyCommand returns the command that shows a notification on goos, or
+// an empty name if there is none.
+func notifyCommand(goos, title, message string) (string, []string) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		return "osascript", []string{"-e", script}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{title, message}
+	case "windows":
+		quote := func(s string) string { return "'" + strings.Replace(s, "'", "''", -1) + "'" }
+		script := `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode(` + quote(title) + `)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode(` + quote(message) + `)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('Tomato').Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	}
+	return "", nil
+}
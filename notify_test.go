@@ -0,0 +1,169 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotifyCommand(t *testing.T) {
+	for _, test := range []struct {
+		goos, name, args string
+	}{
+		{"darwin", "osascript", `-e|display notification "it's over" with title "Tomato"`},
+		{"linux", "notify-send", "Tomato|it's over"},
+		{"freebsd", "notify-send", "Tomato|it's over"},
+		{"plan9", "", ""},
+	} {
+		name, args := notifyCommand(test.goos, "Tomato", "it's over")
+		if name != test.name || strings.Join(args, "|") != test.args {
+			t.Errorf("%v: %q %q, want %q %q", test.goos, name, args, test.name, test.args)
+		}
+	}
+
+	name, args := notifyCommand("windows", "Tomato", "it's over")
+	if name != "powershell" || len(args) != 4 || args[2] != "-Command" ||
+		!strings.Contains(args[3], "CreateTextNode('Tomato')") || !strings.Contains(args[3], "CreateTextNode('it''s over')") {
+		t.Errorf("windows: %q %q", name, args)
+	}
+}
+
+// TestNotify shows the notification of an ended work interval on the host
+// OS, through a stand-in for the notification tool, next to -command.
+func TestNotify(t *testing.T) {
+	name, want := notifyCommand(runtime.GOOS, "Tomato", "Time is over (work), next: short-break")
+	if name == "" {
+		t.Skipf("no notifications on %v", runtime.GOOS)
+	}
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	s, clock, _ := setup(t)
+	Notify, Command = true, "say done"
+	ran := make(chan []string, 10)
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		ran <- append([]string{name}, args...)
+		return exec.Command("/bin/sh", "-c", ":")
+	}
+	do(s.Handler(), "POST", "/action/start", nil)
+	clock.Add(25*time.Minute + time.Second)
+	s.RefreshStatus(false)
+
+	var notified, commanded bool
+	for !notified || !commanded {
+		select {
+		case c := <-ran:
+			switch {
+			case c[0] == name:
+				notified = true
+				if strings.Join(c[1:], "|") != strings.Join(want, "|") {
+					t.Errorf("notification %q, want %q", c[1:], want)
+				}
+			case c[len(c)-1] == Command:
+				commanded = true
+			default:
+				t.Errorf("unexpected command %q", c)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("notified=%v command=%v", notified, commanded)
+		}
+	}
+	s.running.Wait()
+}
+
+// TestNotifyFallback logs the notification when there is no tool to show it.
+func TestNotifyFallback(t *testing.T) {
+	setup(t)
+	t.Setenv("PATH", t.TempDir())
+	logs := &syncBuffer{}
+	log.SetOutput(logs)
+	defer log.SetOutput(ioutil.Discard)
+
+	notify(ModeShortBreak, ModeWork)
+	if want := "Tomato: Time is over (short-break), next: work"; !strings.Contains(logs.String(), want) {
+		t.Errorf("log %q, want %q", logs, want)
+	}
+}
+
+// TestRichNotify clicks the buttons of the notification, before and after
+// the timer moved on.
+func TestRichNotify(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "alerter"), []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	s, _, _ := setup(t)
+	var click string
+	var ran []string
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		ran = append(ran, name)
+		return exec.Command("/bin/sh", "-c", "echo "+click)
+	}
+	h := s.Handler()
+	do(h, "POST", "/action/skip", nil)
+
+	// A click on a notification of the stopped short break.
+	click = "Start short-break"
+	s.richNotify(ModeWork, ModeShortBreak, LabelStopped)
+	if s.state != StateRunning || s.mode != ModeShortBreak || len(ran) != 1 || ran[0] != "alerter" {
+		t.Errorf("after Start: %v %v, ran %q", s.mode, stateLabel(s.state), ran)
+	}
+	// The same click once the break runs does not pause it.
+	s.richNotify(ModeWork, ModeShortBreak, LabelStopped)
+	if s.state != StateRunning {
+		t.Errorf("after a stale Start: %v", stateLabel(s.state))
+	}
+	click = "Skip"
+	s.richNotify(ModeWork, ModeShortBreak, LabelStopped)
+	if s.mode != ModeShortBreak || s.state != StateRunning {
+		t.Errorf("after a stale Skip: %v %v", s.mode, stateLabel(s.state))
+	}
+	do(h, "POST", "/action/stop", nil)
+	s.richNotify(ModeWork, ModeShortBreak, LabelStopped)
+	if s.mode != ModeWork || s.state != StateStopped {
+		t.Errorf("after Skip: %v %v", s.mode, stateLabel(s.state))
+	}
+
+	// Without alerter, a plain notification.
+	t.Setenv("PATH", t.TempDir())
+	ran = nil
+	s.richNotify(ModeWork, ModeShortBreak, LabelStopped)
+	if len(ran) != 1 || ran[0] != "osascript" {
+		t.Errorf("without alerter: ran %q", ran)
+	}
+}
+
+// TestNotifyDryRun checks that -dry-run shows no notifications.
+func TestNotifyDryRun(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"alerter", "osascript", "notify-send", "powershell"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	s, _, commands := setup(t)
+	DryRun = true
+	logs := &syncBuffer{}
+	log.SetOutput(logs)
+	defer log.SetOutput(ioutil.Discard)
+	notify(ModeWork, ModeShortBreak)
+	s.richNotify(ModeWork, ModeShortBreak, LabelStopped)
+	if c := commands.Take(); len(c) != 0 {
+		t.Errorf("ran %q", c)
+	}
+	if n := strings.Count(logs.String(), "Dry run, not showing notification"); n != 2 {
+		t.Errorf("%v notifications logged, want 2:\n%v", n, logs)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// options are the values of the options that can change while running, by
+// flag name, e.g. {"work": "25m"}. A SIGHUP or a profile switch builds new
+// options, checks them with parse and only then puts them in place, so a
+// bad file changes nothing.
+type options map[string]string
+
+// reloadable reports whether the option name can change while running. The
+// others are only read on start.
+func reloadable(name string) bool {
+	switch name {
+	case "n", "work", "short", "long", "tick", "warn", "ack-reminder", "min-break", "eye-every",
+		"format", "icon1", "icon2", "icon3", "icon-overtime", "icon-alarm":
+		return true
+	}
+	return name == "command" || strings.HasSuffix(name, "-command")
+}
+
+// scheduleOption reports whether the option name is part of the schedule that
+// -cycle replaces.
+func scheduleOption(name string) bool {
+	return name == "n" || name == "work" || name == "short" || name == "long"
+}
+
+// currentOptions returns the values of the reloadable flags.
+func currentOptions() options {
+	o := options{}
+	flag.VisitAll(func(f *flag.Flag) {
+		if reloadable(f.Name) {
+			o[f.Name] = f.Value.String()
+		}
+	})
+	return o
+}
+
+// with returns a copy of o with values set, except those in fixedFlags.
+// Options that can not be reloaded must keep their current value. source
+// names the values in errors.
+func (o options) with(values map[string]interface{}, source string) (options, error) {
+	next := options{}
+	for name, value := range o {
+		next[name] = value
+	}
+	for name, value := range values {
+		f := flag.Lookup(name)
+		if f == nil || name == "config" {
+			return nil, fmt.Errorf("Unknown option %q in %v", name, source)
+		}
+		str, err := optionString(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for %q in %v", name, source)
+		}
+		switch {
+		case fixedFlags[name]:
+		case !reloadable(name):
+			if str != f.Value.String() {
+				return nil, fmt.Errorf("Option %q in %v can only be changed by a restart", name, source)
+			}
+		case len(Cycle) > 0 && scheduleOption(name):
+			if str != o[name] {
+				return nil, fmt.Errorf("Option %q in %v can not be changed with -cycle", name, source)
+			}
+		default:
+			next[name] = str
+		}
+	}
+	return next, nil
+}
+
+// set sets the flags to o, e.g. for "tomato config init". It does not
+// validate the values; see parse.
+func (o options) set() {
+	for name, value := range o {
+		flag.Lookup(name).Value.Set(value)
+	}
+}
+
+// settings are options checked by parse, ready to take effect together.
+type settings struct {
+	options
+
+	n                 int
+	work, short, long time.Duration
+	tick              time.Duration
+	warn, ackReminder time.Duration
+	minBreak          time.Duration
+	minBreakPercent   int
+	eyeEvery          time.Duration
+	icons             *iconData // nil without URL
+}
+
+// parse validates every option of o, and loads the icons when URL is set.
+func (o options) parse() (*settings, error) {
+	st := &settings{options: o}
+	tick, err := strconv.Atoi(o["tick"])
+	if err != nil || tick <= 10 || tick >= 1000 {
+		return nil, fmt.Errorf("Invalid ticker value (must between 10 and 1000)")
+	}
+	st.tick = time.Duration(tick) * time.Millisecond
+	if st.n, err = strconv.Atoi(o["n"]); err != nil || st.n < 0 {
+		return nil, fmt.Errorf("Invalid number of intervals (%v)", o["n"])
+	}
+	for _, d := range []struct {
+		name string
+		d    *time.Duration
+	}{
+		{"work", &st.work},
+		{"short", &st.short},
+		{"long", &st.long},
+		{"ack-reminder", &st.ackReminder},
+		{"eye-every", &st.eyeEvery},
+	} {
+		if *d.d, err = parseDuration(o[d.name]); err != nil {
+			return nil, err
+		}
+	}
+	if o["warn"] != "" {
+		if st.warn, err = parseDuration(o["warn"]); err != nil {
+			return nil, err
+		}
+	}
+	if str := o["min-break"]; strings.HasSuffix(str, "%") {
+		n, err := strconv.Atoi(strings.TrimSuffix(str, "%"))
+		if err != nil || n <= 0 || n > 100 {
+			return nil, fmt.Errorf("Invalid percentage for -min-break (%v)", str)
+		}
+		st.minBreakPercent = n
+	} else if str != "" {
+		if st.minBreak, err = parseDuration(str); err != nil {
+			return nil, err
+		}
+	}
+	if o["format"] != "" {
+		if err := validateFormat(o["format"]); err != nil {
+			return nil, err
+		}
+	}
+	if URL != "" {
+		if st.icons, err = loadIcons(o); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// apply puts st in place of the current options. With -cycle, the schedule
+// is kept. The caller must hold s.mu of a running server.
+func (st *settings) apply() {
+	for name, value := range st.options {
+		if len(Cycle) > 0 && scheduleOption(name) {
+			continue
+		}
+		flag.Lookup(name).Value.Set(value)
+	}
+	if len(Cycle) == 0 {
+		N, DurationWork, DurationShortBreak, DurationLongBreak = st.n, st.work, st.short, st.long
+	}
+	Tick = st.tick
+	WarnBefore, AckReminder, EyeEvery = st.warn, st.ackReminder, st.eyeEvery
+	MinBreak, MinBreakPercent = st.minBreak, st.minBreakPercent
+	if st.icons != nil {
+		st.icons.apply()
+	}
+}
+
+// useSettings puts st in place and sends the status again even if it looks
+// the same. New durations and N apply from the next interval; a running timer
+// keeps its end time.
+func (s *Server) useSettings(st *settings) {
+	tick := Tick
+	st.apply()
+	if Tick != tick {
+		s.setTick(Tick)
+	}
+	forgetLastRequest()
+	s.shown = shownStatus{}
+}
+
+// setOption changes the option name while running, e.g. by PUT /config. The
+// new value is kept by later reloads and profile switches unless they set
+// the option themselves.
+func (s *Server) setOption(name, value string) {
+	flag.Lookup(name).Value.Set(value)
+	s.base[name] = value
+}
+
+// setTick makes the ticker send updates every d.
+func (s *Server) setTick(d time.Duration) {
+	// Replace a new tick the ticker has not picked up yet.
+	select {
+	case <-s.retick:
+	default:
+	}
+	s.retick <- d
+}
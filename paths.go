@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// configDir returns the directory searched for a config file when -config
+// is not given: $XDG_CONFIG_HOME/tomato or ~/.config/tomato, and
+// ~/Library/Application Support/tomato on macOS.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tomato"), nil
+}
+
+// dataDir returns the directory for the state and history files:
+// $XDG_DATA_HOME/tomato or ~/.local/share/tomato, and the same directory as
+// configDir on macOS.
+func dataDir() (string, error) {
+	if runtime.GOOS == "darwin" {
+		return configDir()
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "tomato"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "tomato"), nil
+}
+
+// findConfig returns config.toml or config.json in configDir, or "" if
+// there is neither.
+func findConfig() string {
+	dir, err := configDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range []string{"config.toml", "config.json"} {
+		filename := filepath.Join(dir, name)
+		if _, err := os.Stat(filename); err == nil {
+			return filename
+		}
+	}
+	return ""
+}
+
+// setDataFiles points -state and -history into dataDir unless they are in
+// set, or the history is kept in memory.
+func setDataFiles(set map[string]bool) error {
+	history := !set["history"] && StorageBackend != "memory"
+	if set["state"] && !history {
+		return nil
+	}
+	dir, err := dataDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if !set["state"] {
+		StateFile = filepath.Join(dir, "state.json")
+	}
+	if history {
+		HistoryFile = filepath.Join(dir, "history.jsonl")
+		if StorageBackend == "sqlite" {
+			HistoryFile = filepath.Join(dir, "history.db")
+		}
+	}
+	return nil
+}
+
+// configDirHelp returns configDir for the usage of -config.
+func configDirHelp() string {
+	if runtime.GOOS == "darwin" {
+		return "~/Library/Application Support/tomato"
+	}
+	return "$XDG_CONFIG_HOME/tomato or ~/.config/tomato"
+}
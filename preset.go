@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Presets are the named schedules that -preset and /preset/ switch to. The
+// built-in ones can be replaced or extended by the "presets" key of -config.
+var Presets = map[string]scheduleConfig{
+	"classic":   {N: newInt(4), Durations: scheduleDurations{Work: "25m", ShortBreak: "5m", LongBreak: "15m"}},
+	"52-17":     {N: newInt(4), Durations: scheduleDurations{Work: "52m", ShortBreak: "17m", LongBreak: "17m"}},
+	"ultradian": {N: newInt(4), Durations: scheduleDurations{Work: "90m", ShortBreak: "20m", LongBreak: "20m"}},
+	"10-2":      {N: newInt(4), Durations: scheduleDurations{Work: "10m", ShortBreak: "2m", LongBreak: "2m"}},
+}
+
+func newInt(i int) *int {
+	return &i
+}
+
+// setFlagsFromPreset sets -work, -short, -long and -n from the preset name,
+// over those of -config but not those in fixedFlags. The options it sets join
+// fixedFlags, so a SIGHUP or a profile does not change them either.
+func setFlagsFromPreset(name string) error {
+	preset, ok := Presets[name]
+	if !ok {
+		return fmt.Errorf("Unknown preset %q (have %v)", name, strings.Join(presetNames(), ", "))
+	}
+	values := map[string]string{
+		"work":  preset.Durations.Work,
+		"short": preset.Durations.ShortBreak,
+		"long":  preset.Durations.LongBreak,
+	}
+	if preset.N != nil {
+		values["n"] = strconv.Itoa(*preset.N)
+	}
+	for name, value := range values {
+		if fixedFlags[name] || value == "" {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("Invalid value %q for %q in preset: %v", value, name, err)
+		}
+	}
+	for name, value := range values {
+		if value != "" {
+			fixedFlags[name] = true
+		}
+	}
+	return nil
+}
+
+// checkPresets validates every preset against the schedule given on the
+// command line.
+func checkPresets() error {
+	for name, preset := range Presets {
+		if _, _, _, _, err := preset.resolve(); err != nil {
+			return fmt.Errorf("Invalid preset %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// presetNames returns the names of Presets in order.
+func presetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preset switches to the schedule of the preset named by the path, e.g.
+// POST /preset/afternoon, like PUT /config with the preset as body.
+func (s *Server) Preset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/preset/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	preset, ok := Presets[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown preset %q (have %v)", name, strings.Join(presetNames(), ", ")), http.StatusNotFound)
+		return
+	}
+	s.setSchedule(w, preset)
+}
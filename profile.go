@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var (
+	// Profile is the name of the profile in use, or empty.
+	Profile string
+
+	// Profiles are the named sets of options from the "profiles" key of
+	// -config, e.g. {"writing": {"work": "50m", "command": "say done"}}.
+	// Only options that can be reloaded may be set by a profile.
+	Profiles map[string]map[string]interface{}
+)
+
+// checkProfiles validates every profile of profiles on top of base.
+func checkProfiles(profiles map[string]map[string]interface{}, base options) error {
+	for name := range profiles {
+		o, err := profileOptions(profiles, base, name)
+		if err == nil {
+			_, err = o.parse()
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid profile %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// profileNames returns the names of Profiles in order.
+func profileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileOptions returns base with the options of the profile name in
+// profiles. Options in fixedFlags are kept.
+func profileOptions(profiles map[string]map[string]interface{}, base options, name string) (options, error) {
+	values, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown profile %q (have %v)", name, strings.Join(profileNames(), ", "))
+	}
+	return base.with(values, fmt.Sprintf("profile %q", name))
+}
+
+// Profile switches to the profile named by the path, e.g. POST
+// /profile/writing. Options the previous profile set and this one does not
+// go back to those of -config. Its durations apply from the next interval.
+func (s *Server) Profile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/profile/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := Profiles[name]; !ok {
+		http.Error(w, fmt.Sprintf("Unknown profile %q (have %v)", name, strings.Join(profileNames(), ", ")), http.StatusNotFound)
+		return
+	}
+	o, err := profileOptions(Profiles, s.base, name)
+	var st *settings
+	if err == nil {
+		st, err = o.parse()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	Profile = name
+	s.useSettings(st)
+	log.Printf("Profile %v: Interval=%v ShortBreak=%v LongBreak=%v N=%v", name, DurationWork, DurationShortBreak, DurationLongBreak, N)
+	fmt.Fprint(w, s.refreshStatus(true))
+}
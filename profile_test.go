@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProfile(t *testing.T) {
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer btt.Close()
+	s, _, _ := setup(t)
+	URL = btt.URL
+	defer func() {
+		s.requests.Wait()
+		URL = ""
+	}()
+	red, err := loadIcon("", "red.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orange, err := loadIcon("", "orange.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Profiles = map[string]map[string]interface{}{
+		"writing": {"work": "50m", "command": "say done", "icon1": "orange.png"},
+		"reading": {"short": "10m"},
+	}
+	s.base = currentOptions()
+	h := s.Handler()
+
+	if rec := do(h, "POST", "/profile/writing", nil); rec.Code != http.StatusOK {
+		t.Fatalf("POST /profile/writing: %v %v", rec.Code, rec.Body)
+	}
+	if Profile != "writing" || DurationWork != 50*time.Minute || Command != "say done" || Icon1Data != orange {
+		t.Errorf("writing: profile=%q work=%v command=%q orange icon=%v", Profile, DurationWork, Command, Icon1Data == orange)
+	}
+	if rec := do(h, "POST", "/profile/reading", nil); rec.Code != http.StatusOK {
+		t.Fatalf("POST /profile/reading: %v %v", rec.Code, rec.Body)
+	}
+	if Profile != "reading" || DurationWork != 25*time.Minute || DurationShortBreak != 10*time.Minute || Command != "" || Icon1Data != red {
+		t.Errorf("reading: profile=%q work=%v short=%v command=%q red icon=%v", Profile, DurationWork, DurationShortBreak, Command, Icon1Data == red)
+	}
+
+	for target, code := range map[string]int{
+		"/profile/missing": http.StatusNotFound,
+		"/profile/":        http.StatusNotFound,
+	} {
+		if rec := do(h, "POST", target, nil); rec.Code != code {
+			t.Errorf("POST %v: %v, want %v", target, rec.Code, code)
+		}
+	}
+	if rec := do(h, "GET", "/profile/writing", nil); rec.Code != http.StatusNotFound || Profile != "reading" {
+		t.Errorf("GET /profile/writing: %v profile=%q", rec.Code, Profile)
+	}
+}
+
+// TestProfileFixed checks that options given on the command line win over
+// profiles, and that PUT /config is kept across profile switches.
+func TestProfileFixed(t *testing.T) {
+	s, _, _ := setup(t)
+	fixedFlags = map[string]bool{"work": true}
+	Profiles = map[string]map[string]interface{}{
+		"a": {"work": "50m", "short": "10m"},
+		"b": {"long": "20m"},
+	}
+	s.base = currentOptions()
+	h := s.Handler()
+
+	do(h, "POST", "/profile/a", nil)
+	if DurationWork != 25*time.Minute || DurationShortBreak != 10*time.Minute {
+		t.Errorf("a: work=%v short=%v, want the fixed 25m and 10m", DurationWork, DurationShortBreak)
+	}
+	if rec := do(h, "PUT", "/config", strings.NewReader(`{"n": 6}`)); rec.Code != http.StatusOK {
+		t.Fatalf("PUT /config: %v %v", rec.Code, rec.Body)
+	}
+	do(h, "POST", "/profile/b", nil)
+	if N != 6 || DurationShortBreak != 5*time.Minute || DurationLongBreak != 20*time.Minute {
+		t.Errorf("b: n=%v short=%v long=%v", N, DurationShortBreak, DurationLongBreak)
+	}
+}
+
+func TestCheckProfiles(t *testing.T) {
+	setup(t)
+	for _, test := range []struct {
+		profiles map[string]map[string]interface{}
+		want     string
+	}{
+		{map[string]map[string]interface{}{"a": {"work": "50m"}, "b": {"n": json.Number("2"), "format": "{timer}"}}, ""},
+		{map[string]map[string]interface{}{"a": {"work": "50m"}, "b": {"short": "abc"}}, `Invalid profile "b"`},
+		{map[string]map[string]interface{}{"a": {"listen": ":8080"}}, `Invalid profile "a": Option "listen" in profile "a" can only be changed by a restart`},
+		{map[string]map[string]interface{}{"a": {"nope": 1}}, `Invalid profile "a": Unknown option "nope"`},
+	} {
+		err := checkProfiles(test.profiles, currentOptions())
+		if got := fmt.Sprint(err); test.want == "" && err != nil || test.want != "" && !strings.HasPrefix(got, test.want) {
+			t.Errorf("%v: error %v, want %q", test.profiles, err, test.want)
+		}
+	}
+}
+
+// TestProfileRace switches profiles while other requests read the options.
+// Run it with -race.
+func TestProfileRace(t *testing.T) {
+	s, _, _ := setup(t)
+	Profiles = map[string]map[string]interface{}{
+		"a": {"work": "50m", "n": json.Number("3")},
+		"b": {"work": "30m", "n": json.Number("2")},
+	}
+	s.base = currentOptions()
+	h := s.Handler()
+
+	var wg sync.WaitGroup
+	for _, target := range []string{"/profile/a", "/profile/b", "/status", "/config"} {
+		wg.Add(1)
+		go func(target string) {
+			defer wg.Done()
+			method := "POST"
+			if !strings.HasPrefix(target, "/profile/") {
+				method = "GET"
+			}
+			for i := 0; i < 50; i++ {
+				do(h, method, target, nil)
+			}
+		}(target)
+	}
+	wg.Wait()
+
+	want := map[string]time.Duration{"a": 50 * time.Minute, "b": 30 * time.Minute}[Profile]
+	if DurationWork != want || N != map[string]int{"a": 3, "b": 2}[Profile] {
+		t.Errorf("profile=%q work=%v n=%v", Profile, DurationWork, N)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// Quiet are the quiet hours of -quiet, or nil.
+	Quiet *hourDurations
+
+	// QuietCommand replaces the end-of-timer commands in quiet hours.
+	QuietCommand string
+)
+
+// parseQuiet parses quiet hours such as "22:00-08:00".
+func parseQuiet(str string) (*hourDurations, error) {
+	parts := strings.Split(str, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("Invalid quiet hours %q, expected e.g. 22:00-08:00", str)
+	}
+	from, err := parseClock(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseClock(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return nil, err
+	}
+	return &hourDurations{from: from, until: until}, nil
+}
+
+// quiet reports whether it is quiet hours now.
+func quiet() bool {
+	return Quiet != nil && Quiet.contains(timeNow())
+}
+
+// quietCommand returns -quiet-command instead of command in quiet hours.
+func quietCommand(command string) string {
+	if quiet() {
+		return QuietCommand
+	}
+	return command
+}
+
+// quietHours formats Quiet for the log.
+func quietHours() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+	return clock(Quiet.from) + "-" + clock(Quiet.until)
+}
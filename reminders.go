@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reminder is a recurring command that runs beside the pomodoro cycle, e.g.
+// a reminder to drink water every 45 minutes.
+type reminder struct {
+	ID      int       `json:"id"`
+	Every   string    `json:"every"`
+	Command string    `json:"command"`
+	Next    time.Time `json:"next"`
+
+	every time.Duration
+}
+
+// parse validates r and sets the next run from now.
+func (r *reminder) parse() error {
+	d, err := parseDuration(r.Every)
+	if err != nil {
+		return err
+	}
+	if r.Command == "" {
+		return fmt.Errorf("Missing command")
+	}
+	r.every = d
+	r.Every = d.String()
+	r.Next = timeNow().Add(d)
+	return nil
+}
+
+// Reminders manages the reminders: GET /reminders lists them, POST
+// /reminders adds one from a body like {"every":"45m","command":"say
+// water"}, and GET, PUT or DELETE /reminders/ID read, replace or remove one.
+func (s *Server) Reminders(w http.ResponseWriter, r *http.Request) {
+	str := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/reminders"), "/")
+	id := -1
+	if str != "" {
+		var err error
+		if id, err = strconv.Atoi(str); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	var req reminder
+	switch {
+	case id < 0 && r.Method == "GET", id >= 0 && (r.Method == "GET" || r.Method == "DELETE"):
+	case id < 0 && r.Method == "POST", id >= 0 && r.Method == "PUT":
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := req.parse(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id < 0 {
+		if r.Method == "POST" {
+			s.lastReminder++
+			req.ID = s.lastReminder
+			s.reminders = append(s.reminders, &req)
+			w.WriteHeader(http.StatusCreated)
+			data, _ := json.Marshal(req)
+			w.Write(data)
+			return
+		}
+		data, _ := json.Marshal(append([]*reminder{}, s.reminders...))
+		w.Write(data)
+		return
+	}
+
+	i := 0
+	for i < len(s.reminders) && s.reminders[i].ID != id {
+		i++
+	}
+	if i == len(s.reminders) {
+		http.Error(w, fmt.Sprintf("Unknown reminder %v", id), http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case "PUT":
+		req.ID = id
+		s.reminders[i] = &req
+	case "DELETE":
+		s.reminders = append(s.reminders[:i], s.reminders[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	data, _ := json.Marshal(s.reminders[i])
+	w.Write(data)
+}
+
+// checkReminders runs the commands of the reminders that are due, except in
+// quiet hours, and schedules their next run.
+func (s *Server) checkReminders() {
+	now := timeNow()
+	for _, r := range s.reminders {
+		if now.Before(r.Next) {
+			continue
+		}
+		if !quiet() {
+			s.runCommand(r.Command)
+		}
+		// Skip the runs missed while the timer was not refreshed.
+		for !r.Next.After(now) {
+			r.Next = r.Next.Add(r.every)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// scheduledStart is a work interval queued by POST /schedule.
+type scheduledStart struct {
+	At     time.Time `json:"at"`
+	Preset string    `json:"preset,omitempty"`
+}
+
+// Schedule lists the pending starts on GET, queues one on POST and removes
+// them on DELETE.
+//
+// POST takes the local time of day as at, e.g. at=15:00, and optionally a
+// preset to switch to. A time that has passed today is taken as tomorrow.
+// DELETE removes the start at the given time, or all of them without at.
+func (s *Server) Schedule(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET", "POST", "DELETE":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	var at time.Time
+	if str := r.FormValue("at"); str != "" {
+		var err error
+		if at, err = nextClockTime(str, timeNow()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else if r.Method == "POST" {
+		http.Error(w, "Missing at, expected e.g. at=15:00", http.StatusBadRequest)
+		return
+	}
+	preset := r.FormValue("preset")
+	if _, ok := Presets[preset]; preset != "" && !ok {
+		http.Error(w, fmt.Sprintf("Unknown preset %q", preset), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case "POST":
+		s.scheduled = append(s.scheduled, scheduledStart{at, preset})
+		sort.Slice(s.scheduled, func(i, j int) bool { return s.scheduled[i].At.Before(s.scheduled[j].At) })
+	case "DELETE":
+		pending := s.scheduled[:0]
+		for _, start := range s.scheduled {
+			if !at.IsZero() && !start.At.Equal(at) {
+				pending = append(pending, start)
+			}
+		}
+		s.scheduled = pending
+	}
+
+	data, _ := json.Marshal(append([]scheduledStart{}, s.scheduled...))
+	w.Write(data)
+}
+
+// checkSchedule starts the first scheduled work interval that is due. The
+// current interval, if any, is stopped, except a work interval under
+// -strict: the start then waits until it is over. It reports whether it
+// started one.
+func (s *Server) checkSchedule() bool {
+	if len(s.scheduled) == 0 || timeNow().Before(s.scheduled[0].At) || s.strictWork() {
+		return false
+	}
+	start := s.scheduled[0]
+	s.scheduled = s.scheduled[1:]
+
+	if start.Preset != "" {
+		if len(Cycle) > 0 {
+			log.Printf("Not switching to preset %q, the schedule is set by -cycle", start.Preset)
+		} else if err := s.applySchedule(Presets[start.Preset]); err != nil {
+			log.Printf("Error while switching to preset %q: %v", start.Preset, err)
+		}
+	}
+	log.Printf("Starting the work interval scheduled at %v", start.At.Format("15:04:05"))
+	if s.state != StateStopped {
+		s.endInterval(OutcomeStopped, timeNow())
+		s.state = StateStopped
+	}
+	s.mode = ModeWork
+	s.flowBreak = 0
+	s.toggle()
+	return true
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"time"
+)
+
+// selfTest runs the server through a complete cycle of N work intervals and
+// a long break using a fake clock, checking every transition and command
+// hook along the way. With N=0, there is no long break and it runs one work
+// interval and a short break. It reports to stdout and returns whether all
+// checks passed; it fails if none ran.
+func selfTest() bool {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+
+	var commands []string
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		commands = append(commands, args[len(args)-1])
+		return exec.Command("/bin/sh", "-c", ":")
+	}
+
+	Command = "end"
+	CommandWork, CommandShortBreak, CommandLongBreak = "", "", ""
+	CommandOnStart = "start"
+	CommandOnPause, CommandOnResume, CommandOnWarn, CommandOnGoal = "", "", "", ""
+	CommandAsync = false
+	URL, TextFile, StateFile, HistoryFile, Webhook, StorageBackend = "", "", "", "", "", ""
+	AutoAdvance, AutoBreak, AutoWork = false, false, false
+	RichNotify, Notify, Stopwatch, RequireAck, Strict, Overtime, Flowtime = false, false, false, false, false, false, false
+	Token = ""
+	DryRun = false
+	MinBreak, MinBreakPercent = 0, 0
+	Cycle, WeekdayDurations, HourDurations = nil, nil, nil
+	Quiet, AlarmCommand, EyeCommand = nil, "", ""
+
+	s := NewServer()
+	h := s.Handler()
+
+	passed, checks := true, 0
+	check := func(name string, ok bool, format string, args ...interface{}) {
+		checks++
+		result := "PASS"
+		if !ok {
+			result = "FAIL"
+			passed = false
+		}
+		fmt.Printf("%v %v: %v\n", result, name, fmt.Sprintf(format, args...))
+	}
+
+	steps := 2 * N
+	if N == 0 {
+		steps = 2
+	}
+	for i := 0; i < steps; i++ {
+		mode := s.mode
+		wantMode, wantCount := ModeWork, 0
+		switch {
+		case mode == ModeWork && (N == 0 || s.count+1 < N):
+			wantMode, wantCount = ModeShortBreak, s.count+1
+		case mode == ModeWork:
+			wantMode, wantCount = ModeLongBreak, N
+		case mode == ModeShortBreak:
+			wantMode, wantCount = ModeWork, s.count
+		}
+
+		commands = nil
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", "/action/start", nil))
+		check(fmt.Sprintf("start %v", mode),
+			rec.Code == http.StatusOK && s.state == StateRunning && equalStrings(commands, "start"),
+			"state=%v commands=%q", s.state, commands)
+
+		commands = nil
+		now = now.Add(mode.Duration() + time.Second)
+		s.RefreshStatus(false)
+		check(fmt.Sprintf("%v -> %v", mode, wantMode),
+			s.mode == wantMode && s.count == wantCount && s.state == StateStopped && equalStrings(commands, "end"),
+			"mode=%v count=%v state=%v commands=%q", s.mode, s.count, s.state, commands)
+
+		if mode == ModeLongBreak {
+			break
+		}
+	}
+
+	s.running.Wait()
+
+	if checks == 0 {
+		fmt.Println("FAIL no checks ran")
+		passed = false
+	}
+	if passed {
+		fmt.Println("Self-test passed")
+	} else {
+		fmt.Println("Self-test failed")
+	}
+	return passed
+}
+
+func equalStrings(a []string, b ...string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	stdout := os.Stdout
+	devnull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devnull.Close()
+	os.Stdout = devnull
+	defer func() { os.Stdout = stdout }()
+
+	for _, n := range []int{0, 1, 4} {
+		setup(t)
+		N = n
+		if !selfTest() {
+			t.Errorf("-n=%v: self-test failed", n)
+		}
+	}
+}
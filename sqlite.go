@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sqliteSchema creates the table of sqliteStorage. Times are Unix times in
+// nanoseconds, lengths are in seconds.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS sessions (
+	id INTEGER PRIMARY KEY,
+	mode TEXT NOT NULL,
+	started INTEGER NOT NULL,
+	ended INTEGER NOT NULL,
+	outcome TEXT NOT NULL,
+	planned INTEGER NOT NULL DEFAULT 0,
+	paused INTEGER NOT NULL DEFAULT 0,
+	overtime INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS sessions_started ON sessions (started);
+`
+
+// sqliteStorage keeps the records in the sessions table of an SQLite
+// database. It runs the sqlite3 command, so that tomato needs neither cgo
+// nor a driver. Like jsonlStorage, it writes the records from a goroutine of
+// its own; queries wait until they are written.
+type sqliteStorage struct {
+	filename string
+
+	mu      sync.Mutex
+	pending []string       // INSERT statements not run yet
+	wake    chan struct{}  // wakes up write
+	written sync.WaitGroup // for the statements in pending
+}
+
+// newSQLiteStorage creates the sessions table in filename unless it exists.
+func newSQLiteStorage(filename string) *sqliteStorage {
+	st := &sqliteStorage{filename: filename, wake: make(chan struct{}, 1)}
+	if _, err := st.run(sqliteSchema); err != nil {
+		log.Printf("Error while opening history: %v", err)
+	}
+	go st.write()
+	return st
+}
+
+// run runs the statements in sql and returns the rows of the last one as
+// JSON, or nothing if it has none.
+func (st *sqliteStorage) run(sql string) ([]byte, error) {
+	cmd := exec.Command("sqlite3", "-bail", "-json", st.filename)
+	cmd.Stdin = strings.NewReader(sql)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("sqlite3: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, nil
+}
+
+// query runs sql and decodes its rows into rows.
+func (st *sqliteStorage) query(sql string, rows interface{}) error {
+	st.written.Wait()
+	out, err := st.run(sql)
+	if err != nil || len(bytes.TrimSpace(out)) == 0 {
+		return err
+	}
+	return json.Unmarshal(out, rows)
+}
+
+// SaveSession hands rec to write. Errors while writing are logged.
+func (st *sqliteStorage) SaveSession(rec historyRecord) error {
+	stmt := fmt.Sprintf("INSERT INTO sessions (mode, started, ended, outcome, planned, paused, overtime) VALUES (%v, %d, %d, %v, %d, %d, %d);",
+		sqlQuote(string(rec.Mode)), rec.Start.UnixNano(), rec.End.UnixNano(), sqlQuote(rec.Outcome), rec.Planned, rec.Paused, rec.Overtime)
+
+	st.mu.Lock()
+	st.pending = append(st.pending, stmt)
+	st.written.Add(1)
+	st.mu.Unlock()
+	select {
+	case st.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// write runs the pending statements in one transaction.
+func (st *sqliteStorage) write() {
+	for range st.wake {
+		st.mu.Lock()
+		stmts := st.pending
+		st.pending = nil
+		st.mu.Unlock()
+		if len(stmts) == 0 {
+			continue
+		}
+
+		sql := "BEGIN;\n" + strings.Join(stmts, "\n") + "\nCOMMIT;\n"
+		if _, err := st.run(sql); err != nil {
+			log.Printf("Error while writing history: %v", err)
+		}
+		st.written.Add(-len(stmts))
+	}
+}
+
+func (st *sqliteStorage) ListSessions(filter sessionFilter) ([]historyRecord, error) {
+	sql := "SELECT mode, started, ended, outcome, planned, paused, overtime FROM sessions" + sqlWhere(filter) + " ORDER BY id DESC"
+	switch {
+	case filter.Limit > 0:
+		sql += fmt.Sprintf(" LIMIT %d OFFSET %d", filter.Limit, filter.Offset)
+	case filter.Offset > 0:
+		sql += fmt.Sprintf(" LIMIT -1 OFFSET %d", filter.Offset)
+	}
+	var rows []struct {
+		Mode                      Mode
+		Started, Ended            int64
+		Outcome                   string
+		Planned, Paused, Overtime int
+	}
+	if err := st.query(sql+";", &rows); err != nil {
+		return nil, err
+	}
+
+	// The rows are newest first.
+	records := make([]historyRecord, len(rows))
+	for i, row := range rows {
+		records[len(rows)-1-i] = historyRecord{
+			Mode: row.Mode, Start: time.Unix(0, row.Started), End: time.Unix(0, row.Ended), Outcome: row.Outcome,
+			Planned: row.Planned, Paused: row.Paused, Overtime: row.Overtime,
+		}
+	}
+	return records, nil
+}
+
+func (st *sqliteStorage) Stats(filter sessionFilter) (map[Mode]sessionStats, error) {
+	// Like statsOf, the time of each interval is cut to whole seconds.
+	sql := fmt.Sprintf("SELECT mode, COUNT(*) AS count, SUM(outcome = %v) AS completed, SUM((ended - started) / 1000000000 - paused) AS time FROM sessions%v GROUP BY mode;",
+		sqlQuote(OutcomeCompleted), sqlWhere(filter))
+	var rows []struct {
+		Mode Mode
+		sessionStats
+	}
+	if err := st.query(sql, &rows); err != nil {
+		return nil, err
+	}
+	stats := map[Mode]sessionStats{}
+	for _, row := range rows {
+		stats[row.Mode] = row.sessionStats
+	}
+	return stats, nil
+}
+
+// Close waits until the pending statements are run.
+func (st *sqliteStorage) Close() error {
+	st.written.Wait()
+	return nil
+}
+
+// sqlWhere returns the WHERE clause for the From, To and Mode of filter.
+func sqlWhere(filter sessionFilter) string {
+	var conds []string
+	if !filter.From.IsZero() {
+		conds = append(conds, fmt.Sprintf("started >= %d", filter.From.UnixNano()))
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, fmt.Sprintf("started < %d", filter.To.UnixNano()))
+	}
+	if filter.Mode != "" {
+		conds = append(conds, "mode = "+sqlQuote(string(filter.Mode)))
+	}
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// sqlQuote returns str as an SQL string literal.
+func sqlQuote(str string) string {
+	return "'" + strings.Replace(str, "'", "''", -1) + "'"
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Storage keeps the intervals that ended, for GET /history. The backend is
+// chosen with -storage. It is guarded by Server.mu.
+type Storage interface {
+	// SaveSession records an interval that ended.
+	SaveSession(rec historyRecord) error
+
+	// ListSessions returns the intervals that match filter, oldest first.
+	ListSessions(filter sessionFilter) ([]historyRecord, error)
+
+	// Stats sums up the intervals that match filter by mode. Limit and
+	// Offset are ignored.
+	Stats(filter sessionFilter) (map[Mode]sessionStats, error)
+
+	// Close writes what is still pending, on shutdown.
+	Close() error
+}
+
+// sessionStats sums up the intervals of one mode.
+type sessionStats struct {
+	Count     int `json:"count"`
+	Completed int `json:"completed"`
+	Time      int `json:"time"` // seconds from start to end, less the pauses
+}
+
+// statsOf sums up the records that match filter.
+func statsOf(records []historyRecord, filter sessionFilter) map[Mode]sessionStats {
+	stats := map[Mode]sessionStats{}
+	for _, rec := range records {
+		if !filter.match(rec) {
+			continue
+		}
+		st := stats[rec.Mode]
+		st.Count++
+		if rec.Outcome == OutcomeCompleted {
+			st.Completed++
+		}
+		st.Time += int(rec.End.Sub(rec.Start)/time.Second) - rec.Paused
+		stats[rec.Mode] = st
+	}
+	return stats
+}
+
+// sessionFilter selects intervals for ListSessions. Zero fields match all.
+type sessionFilter struct {
+	From, To      time.Time // bounds of the start of the interval, To excluded
+	Mode          Mode
+	Limit, Offset int // the last Limit matches after skipping the last Offset
+}
+
+func (f sessionFilter) match(rec historyRecord) bool {
+	return (f.From.IsZero() || !rec.Start.Before(f.From)) &&
+		(f.To.IsZero() || rec.Start.Before(f.To)) &&
+		(f.Mode == "" || rec.Mode == f.Mode)
+}
+
+// apply returns the records, oldest first, that match f.
+func (f sessionFilter) apply(records []historyRecord) []historyRecord {
+	out := []historyRecord{}
+	for _, rec := range records {
+		if f.match(rec) {
+			out = append(out, rec)
+		}
+	}
+	end := len(out) - f.Offset
+	if end < 0 {
+		end = 0
+	}
+	start := 0
+	if f.Limit > 0 && end > f.Limit {
+		start = end - f.Limit
+	}
+	return out[start:end]
+}
+
+// StorageBackend is the name of the Storage of -storage: "memory", "jsonl"
+// to append to HistoryFile, or "sqlite" to keep the records in the SQLite
+// database HistoryFile. It defaults to "jsonl" when HistoryFile is set.
+var StorageBackend string
+
+// checkStorage validates -storage against -history.
+func checkStorage() error {
+	switch StorageBackend {
+	case "":
+	case "memory":
+		if HistoryFile != "" {
+			return fmt.Errorf("-history can not be used with -storage=memory")
+		}
+	case "jsonl", "sqlite":
+		if HistoryFile == "" {
+			return fmt.Errorf("-storage=%v needs -history", StorageBackend)
+		}
+		if _, err := exec.LookPath("sqlite3"); err != nil && StorageBackend == "sqlite" {
+			return fmt.Errorf("-storage=sqlite needs the sqlite3 command: %v", err)
+		}
+	default:
+		return fmt.Errorf("Unknown storage %q (have memory, jsonl, sqlite)", StorageBackend)
+	}
+	return nil
+}
+
+// newStorage returns the Storage of StorageBackend.
+func newStorage() Storage {
+	if StorageBackend == "sqlite" {
+		return newSQLiteStorage(HistoryFile)
+	}
+	if StorageBackend == "jsonl" || StorageBackend == "" && HistoryFile != "" {
+		return newJSONLStorage(HistoryFile)
+	}
+	return &history{}
+}
+
+// SaveSession keeps rec in memory, dropping the oldest record once there
+// are historySize.
+func (h *history) SaveSession(rec historyRecord) error {
+	h.add(rec)
+	return nil
+}
+
+func (h *history) ListSessions(filter sessionFilter) ([]historyRecord, error) {
+	return filter.apply(h.recent(h.len)), nil
+}
+
+func (h *history) Stats(filter sessionFilter) (map[Mode]sessionStats, error) {
+	return statsOf(h.recent(h.len), filter), nil
+}
+
+func (h *history) Close() error {
+	return nil
+}
+
+// jsonlStorage appends every record to a JSON Lines file. Only the last
+// historySize records are kept in memory, read from the end of the file on
+// start; queries that reach further back read the whole file. The lines are
+// written by a goroutine of their own, so that saving does not hold up the
+// server.
+type jsonlStorage struct {
+	filename string
+	recent   history // the last records in the file
+	older    bool    // whether the file has records before recent
+
+	mu      sync.Mutex
+	pending [][]byte       // lines not written yet
+	wake    chan struct{}  // wakes up write
+	written sync.WaitGroup // for the records in pending
+}
+
+// newJSONLStorage reads the last records in filename, skipping lines that
+// can not be parsed, e.g. one cut short by a crash.
+func newJSONLStorage(filename string) *jsonlStorage {
+	st := &jsonlStorage{filename: filename, wake: make(chan struct{}, 1)}
+	if err := st.load(); err != nil {
+		log.Printf("Error while reading history: %v", err)
+	}
+	go st.write()
+	return st
+}
+
+func (st *jsonlStorage) load() error {
+	f, err := os.Open(st.filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lines, older, err := tailLines(f, historySize)
+	if err != nil {
+		return err
+	}
+	st.older = older
+	for _, line := range lines {
+		var rec historyRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			log.Printf("Error while reading history: %v", err)
+			continue
+		}
+		st.recent.add(rec)
+	}
+	return nil
+}
+
+// tailLines returns the last n non-empty lines of f, reading it backwards,
+// and whether there are more lines before them.
+func tailLines(f *os.File, n int) ([][]byte, bool, error) {
+	pos, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, false, err
+	}
+	var data []byte
+	const block = 64 << 10
+	// Once data has n newlines before its end, the last n lines are whole.
+	for pos > 0 && bytes.Count(bytes.TrimRight(data, "\n"), []byte("\n")) < n {
+		size := int64(block)
+		if size > pos {
+			size = pos
+		}
+		pos -= size
+		buf := make([]byte, size, int(size)+len(data))
+		if _, err := f.ReadAt(buf, pos); err != nil {
+			return nil, false, err
+		}
+		data = append(buf, data...)
+	}
+
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		return lines[len(lines)-n:], true, nil
+	}
+	return lines, false, nil
+}
+
+// readAll reads every record in the file, once the pending lines are
+// written. Lines that can not be parsed are skipped.
+func (st *jsonlStorage) readAll() ([]historyRecord, error) {
+	st.written.Wait()
+	f, err := os.Open(st.filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []historyRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec historyRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) == nil {
+			records = append(records, rec)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// SaveSession keeps rec and hands it to write. Errors while writing are
+// logged.
+func (st *jsonlStorage) SaveSession(rec historyRecord) error {
+	st.recent.add(rec)
+	data, _ := json.Marshal(rec)
+
+	st.mu.Lock()
+	st.pending = append(st.pending, data)
+	st.written.Add(1)
+	st.mu.Unlock()
+	select {
+	case st.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// write appends the pending lines to the file.
+func (st *jsonlStorage) write() {
+	for range st.wake {
+		st.mu.Lock()
+		lines := st.pending
+		st.pending = nil
+		st.mu.Unlock()
+		if len(lines) == 0 {
+			continue
+		}
+
+		data := append(bytes.Join(lines, []byte("\n")), '\n')
+		if err := appendFile(st.filename, data); err != nil {
+			log.Printf("Error while writing history: %v", err)
+		}
+		st.written.Add(-len(lines))
+	}
+}
+
+// sessions returns the records in memory if they hold all that filter
+// selects, and otherwise every record in the file. The older records
+// ended before the first one in memory, so a filter from after that end
+// leaves them out, and so does a limit that the records in memory fill,
+// unless the limit is ignored.
+func (st *jsonlStorage) sessions(filter sessionFilter, limit bool) ([]historyRecord, error) {
+	records := st.recent.recent(st.recent.len)
+	if !st.older || len(records) > 0 && !filter.From.IsZero() && !filter.From.Before(records[0].End) {
+		return records, nil
+	}
+	if limit && filter.Limit > 0 {
+		matches := 0
+		for _, rec := range records {
+			if filter.match(rec) {
+				matches++
+			}
+		}
+		if matches >= filter.Limit+filter.Offset {
+			return records, nil
+		}
+	}
+	return st.readAll()
+}
+
+func (st *jsonlStorage) ListSessions(filter sessionFilter) ([]historyRecord, error) {
+	records, err := st.sessions(filter, true)
+	if err != nil {
+		return nil, err
+	}
+	return filter.apply(records), nil
+}
+
+func (st *jsonlStorage) Stats(filter sessionFilter) (map[Mode]sessionStats, error) {
+	records, err := st.sessions(filter, false)
+	if err != nil {
+		return nil, err
+	}
+	return statsOf(records, filter), nil
+}
+
+// Close waits until the pending lines are written.
+func (st *jsonlStorage) Close() error {
+	st.written.Wait()
+	return nil
+}
+
+func appendFile(filename string, data []byte) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRecords() []historyRecord {
+	start := time.Date(2024, time.May, 2, 10, 0, 0, 0, time.UTC)
+	return []historyRecord{
+		{Mode: ModeWork, Start: start, End: start.Add(27 * time.Minute), Outcome: OutcomeCompleted, Planned: 1500, Paused: 120},
+		{Mode: ModeShortBreak, Start: start.Add(27 * time.Minute), End: start.Add(29 * time.Minute), Outcome: OutcomeSkipped, Planned: 300},
+		{Mode: ModeWork, Start: start.Add(29 * time.Minute), End: start.Add(39 * time.Minute), Outcome: OutcomeStopped, Planned: 1500},
+	}
+}
+
+func TestJSONLStorage(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.jsonl")
+	records := testRecords()
+
+	st := newJSONLStorage(filename)
+	for _, rec := range records[:2] {
+		st.SaveSession(rec)
+	}
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// A line cut short by a crash is skipped.
+	if err := appendFile(filename, []byte(`{"mode":"wo`+"\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	st = newJSONLStorage(filename)
+	st.SaveSession(records[2])
+	st.Close()
+	got, _ := st.ListSessions(sessionFilter{Limit: 100})
+	if len(got) != 3 || !got[0].Start.Equal(records[0].Start) || got[2].Outcome != OutcomeStopped {
+		t.Errorf("ListSessions after a restart = %+v", got)
+	}
+	data, _ := ioutil.ReadFile(filename)
+	if lines := strings.Count(string(data), "\n"); lines != 4 {
+		t.Errorf("%v lines in the file, want 4:\n%s", lines, data)
+	}
+}
+
+func TestSQLiteStorage(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip(err)
+	}
+	filename := filepath.Join(t.TempDir(), "history.db")
+	records := testRecords()
+	records[2].Overtime = 90
+	records[0].Mode = "it's" // quoted
+
+	st := newSQLiteStorage(filename)
+	if got, err := st.ListSessions(sessionFilter{}); err != nil || len(got) != 0 {
+		t.Errorf("ListSessions of an empty database = %+v, %v", got, err)
+	}
+	for _, rec := range records[:2] {
+		st.SaveSession(rec)
+	}
+	st.Close()
+
+	st = newSQLiteStorage(filename)
+	st.SaveSession(records[2])
+	got, err := st.ListSessions(sessionFilter{})
+	if err != nil || len(got) != 3 {
+		t.Fatalf("ListSessions after a restart = %+v, %v", got, err)
+	}
+	for i := range got {
+		if got[i].Mode != records[i].Mode || !got[i].Start.Equal(records[i].Start) || !got[i].End.Equal(records[i].End) ||
+			got[i].Outcome != records[i].Outcome || got[i].Planned != records[i].Planned || got[i].Paused != records[i].Paused || got[i].Overtime != records[i].Overtime {
+			t.Errorf("record %v = %+v, want %+v", i, got[i], records[i])
+		}
+	}
+	for _, test := range []struct {
+		filter sessionFilter
+		want   []int
+	}{
+		{sessionFilter{Limit: 2}, []int{1, 2}},
+		{sessionFilter{Limit: 1, Offset: 1}, []int{1}},
+		{sessionFilter{Offset: 2}, []int{0}},
+		{sessionFilter{Mode: ModeWork}, []int{2}},
+		{sessionFilter{From: records[1].Start, To: records[2].Start}, []int{1}},
+	} {
+		got, err := st.ListSessions(test.filter)
+		ok := err == nil && len(got) == len(test.want)
+		for i := 0; ok && i < len(got); i++ {
+			ok = got[i].Start.Equal(records[test.want[i]].Start)
+		}
+		if !ok {
+			t.Errorf("ListSessions(%+v) = %+v, %v, want records %v", test.filter, got, err, test.want)
+		}
+	}
+	st.Close()
+}
+
+// TestJSONLStorageTail checks that only the last records of a long file are
+// kept in memory, and that queries for older ones read the file.
+func TestJSONLStorageTail(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.jsonl")
+	start := time.Date(2024, time.May, 2, 10, 0, 0, 0, time.UTC)
+	record := func(i int) historyRecord {
+		begin := start.Add(time.Duration(i) * time.Minute)
+		return historyRecord{Mode: ModeWork, Start: begin, End: begin.Add(time.Minute), Outcome: OutcomeCompleted, Planned: i}
+	}
+	st := newJSONLStorage(filename)
+	total := historySize + 500
+	for i := 0; i < total; i++ {
+		st.SaveSession(record(i))
+	}
+	st.Close()
+	if st.recent.len != historySize {
+		t.Errorf("%v records in memory, want %v", st.recent.len, historySize)
+	}
+
+	st = newJSONLStorage(filename)
+	if st.recent.len != historySize || !st.older || st.recent.recent(1)[0].Planned != total-1 {
+		t.Fatalf("after a restart: %v records in memory, older=%v", st.recent.len, st.older)
+	}
+	// The default query is served from memory, even with the file gone.
+	data, _ := ioutil.ReadFile(filename)
+	os.Remove(filename)
+	if got, err := st.ListSessions(sessionFilter{Limit: 100}); err != nil || len(got) != 100 || got[99].Planned != total-1 {
+		t.Errorf("ListSessions(limit=100) from memory: %v records, %v", len(got), err)
+	}
+	if got, err := st.ListSessions(sessionFilter{From: start.Add(time.Duration(total-10) * time.Minute)}); err != nil || len(got) != 10 {
+		t.Errorf("ListSessions(from) from memory: %v records, %v", len(got), err)
+	}
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := st.ListSessions(sessionFilter{})
+	if err != nil || len(got) != total || got[0].Planned != 0 {
+		t.Errorf("ListSessions of all: %v records, %v", len(got), err)
+	}
+	got, _ = st.ListSessions(sessionFilter{Limit: 10, Offset: historySize})
+	if len(got) != 10 || got[0].Planned != total-historySize-10 {
+		t.Errorf("ListSessions(limit=10, offset=%v) = %v records", historySize, len(got))
+	}
+	st.SaveSession(record(total))
+	stats, err := st.Stats(sessionFilter{})
+	if err != nil || stats[ModeWork].Count != total+1 {
+		t.Errorf("Stats = %+v, %v, want %v work intervals", stats, err, total+1)
+	}
+	st.Close()
+}
+
+func TestTailLines(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "lines")
+	var data strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&data, "line %v\n", i)
+	}
+	data.WriteString("\ncut sh") // an empty line and one cut short by a crash
+	if err := ioutil.WriteFile(filename, []byte(data.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lines, older, err := tailLines(f, 3)
+	if err != nil || !older || len(lines) != 3 || string(lines[0]) != "line 19998" || string(lines[2]) != "cut sh" {
+		t.Errorf("tailLines(3) = %q, %v, %v", lines, older, err)
+	}
+	lines, older, err = tailLines(f, 30000)
+	if err != nil || older || len(lines) != 20001 || string(lines[0]) != "line 0" {
+		t.Errorf("tailLines(30000) = %v lines, %v, %v", len(lines), older, err)
+	}
+}
+
+func TestStorageStats(t *testing.T) {
+	storages := []Storage{&history{}, newJSONLStorage(filepath.Join(t.TempDir(), "history.jsonl"))}
+	if _, err := exec.LookPath("sqlite3"); err == nil {
+		storages = append(storages, newSQLiteStorage(filepath.Join(t.TempDir(), "history.db")))
+	}
+	for _, st := range storages {
+		for _, rec := range testRecords() {
+			st.SaveSession(rec)
+		}
+		stats, err := st.Stats(sessionFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		work := sessionStats{Count: 2, Completed: 1, Time: 27*60 - 120 + 10*60}
+		if stats[ModeWork] != work || stats[ModeShortBreak] != (sessionStats{Count: 1, Time: 120}) || len(stats) != 2 {
+			t.Errorf("%T: Stats = %+v", st, stats)
+		}
+		stats, _ = st.Stats(sessionFilter{Mode: ModeShortBreak})
+		if len(stats) != 1 || stats[ModeShortBreak].Count != 1 {
+			t.Errorf("%T: Stats of short breaks = %+v", st, stats)
+		}
+		st.Close()
+	}
+}
+
+func TestHistoryStats(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+	do(h, "POST", "/action/start", nil)
+	clock.Add(26 * time.Minute)
+	s.RefreshStatus(false)
+
+	rec := do(h, "GET", "/history/stats?mode=work", nil)
+	if want := `{"work":{"count":1,"completed":1,"time":1500}}`; rec.Body.String() != want {
+		t.Errorf("GET /history/stats = %v, want %v", rec.Body, want)
+	}
+	if rec := do(h, "GET", "/history/stats?mode=nap", nil); rec.Code != 400 {
+		t.Errorf("GET /history/stats?mode=nap: %v", rec.Code)
+	}
+}
+
+func TestCheckStorage(t *testing.T) {
+	defer func() { StorageBackend, HistoryFile = "", "" }()
+	for _, test := range []struct {
+		backend, history string
+		ok               bool
+	}{
+		{"", "", true},
+		{"memory", "", true},
+		{"memory", "h.jsonl", false},
+		{"jsonl", "", false},
+		{"jsonl", "h.jsonl", true},
+		{"sqlite", "", false},
+		{"sqlite", "h.db", true},
+		{"mysql", "", false},
+	} {
+		StorageBackend, HistoryFile = test.backend, test.history
+		if err := checkStorage(); (err == nil) != test.ok {
+			if _, lookErr := exec.LookPath("sqlite3"); lookErr != nil && test.backend == "sqlite" {
+				continue
+			}
+			t.Errorf("-storage=%v -history=%v: %v", test.backend, test.history, err)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
-//go:generate go-bindata -o zbindata.go red.png green.png
+//go:generate go-bindata -o zbindata.go red.png green.png orange.png
 
 import (
+	"bytes"
+	"context"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
 	"flag"
@@ -13,8 +16,14 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
@@ -25,9 +34,17 @@ var (
 	ModeShortBreak Mode = "short-break"
 	ModeLongBreak  Mode = "long-break"
 
-	StateStopped = "[S]"
-	StatePaused  = "[P]"
-	StateRunning = "[R]"
+	// States identify the state of the timer. They are also saved in
+	// StateFile. The labels shown for them are set by -state-stopped etc.
+	StateStopped  = "[S]"
+	StatePaused   = "[P]"
+	StateRunning  = "[R]"
+	StateFinished = "[F]" // ended and waiting for /action/ack, see -require-ack
+
+	LabelStopped  = StateStopped
+	LabelPaused   = StatePaused
+	LabelRunning  = StateRunning
+	LabelFinished = StateFinished
 
 	N        = 4
 	SepColon = ":"
@@ -37,15 +54,148 @@ var (
 	DurationShortBreak time.Duration
 	DurationLongBreak  time.Duration
 
-	Icon1, Icon2, UUID, URL string
-	Icon1Data, Icon2Data    string
-	Command                 string
-	CommandOnStart          string
-	CommandAsync            bool
+	// Tick is the interval of status updates, see -tick.
+	Tick time.Duration
+
+	Icon1, Icon2, Icon3, UUID, URL  string
+	Icon1Data, Icon2Data, Icon3Data string
+	Command                         string
+	CommandWork                     string
+	CommandShortBreak               string
+	CommandLongBreak                string
+	CommandOnStart                  string
+	CommandOnPause                  string
+	CommandOnResume                 string
+	CommandAsync                    bool
+	TextFile                        string
+	TextFileCleanup                 bool
+	StateFile                       string
+	TextPrefix, TextSuffix          string
+	HTTPRetries                     int
+	RichNotify                      bool
+	AutoAdvance                     bool
+	Format                          string
+	Webhook                         string
+	CommandOnWarn                   string
+	WarnBefore                      time.Duration
+	Token                           string
+	TokenProtectReads               bool
+	CatchUp                         bool
+	DryRun                          bool
+	Stopwatch                       bool
+	Goal                            int
+	CommandOnGoal                   string
+	Notify                          bool
+	RequireAck                      bool
+	AckReminder                     time.Duration
+	AutoBreak, AutoWork             bool
+	Strict                          bool
+	MinBreak                        time.Duration
+	MinBreakPercent                 int
+	Overtime                        bool
+	IconOvertime, IconOvertimeData  string
+	Flowtime                        bool
+	FlowRatio                       float64
 
 	httpClient = http.Client{Timeout: 200 * time.Millisecond}
+
+	// timeNow and execCommand are replaced by the self-test.
+	timeNow     = time.Now
+	execCommand = exec.Command
+)
+
+// The options without a global of their own, read by main on start.
+var (
+	flConfig, flListen, flConnect, flPreset, flPort, flURL       *string
+	flDurationWork, flDurationShortBreak, flDurationLongBreak    *string
+	flQuiet, flStartAt, flStartDays, flCycle, flAlarm, flNudge   *string
+	flMinBreak, flEyeEvery, flAckReminder, flWarn, flHTTPTimeout *string
+	flTicker                                                     *int
+	flCheck, flSelfTest                                          *bool
 )
 
+func init() {
+	flConfig = flag.String("config", "", "Read options from a JSON or TOML (.toml) file with flag names as keys, again on SIGHUP (default config.toml or config.json in "+configDirHelp()+")")
+	flListen = flag.String("listen", ":12321", "Address to listen on")
+	flConnect = flag.String("connect", "", "Address of the server controlled by a command (default -listen)")
+
+	flag.IntVar(&N, "n", N, "Number of intervals between long break (0 for no long breaks)")
+	flQuiet = flag.String("quiet", "", "Quiet hours without end-of-timer commands, notifications and updates to BetterTouchTool, e.g. 22:00-08:00")
+	flag.StringVar(&QuietCommand, "quiet-command", "", "Command to run at the end of timer in quiet hours instead")
+	flStartAt = flag.String("start-at", "", "Start a work interval at this time of day if the timer is stopped, e.g. 09:00")
+	flStartDays = flag.String("start-days", "mon,tue,wed,thu,fri", "Days of the week for -start-at")
+	flCycle = flag.String("cycle", "", "Sequence of intervals to repeat instead of -work, -short-break, -long-break and -n, e.g. \"50m work,10m break,50m work,30m long-break\"")
+	flag.StringVar(&SepColon, "colon", SepColon, "Custom separator")
+	flag.StringVar(&SepBreak, "colon-alt", SepBreak, "Alternative separator for break modes")
+	flag.StringVar(&Icon1, "icon1", "", "Icon for work (default red)")
+	flag.StringVar(&Icon2, "icon2", "", "Icon for break session (default green)")
+	flag.StringVar(&Icon3, "icon3", "", "Icon for long break (default -icon2)")
+	flag.StringVar(&Command, "command", "", "Execute command at the end of timer")
+	flag.StringVar(&CommandWork, "work-command", "", "Execute command at the end of work interval (default -command)")
+	flag.StringVar(&CommandShortBreak, "short-command", "", "Execute command at the end of short break (default -command)")
+	flag.StringVar(&CommandLongBreak, "long-command", "", "Execute command at the end of long break (default -command)")
+	flag.StringVar(&CommandOnStart, "start-command", "", "Execute command on start of timer")
+	flag.StringVar(&CommandOnPause, "pause-command", "", "Execute command when the timer is paused")
+	flag.StringVar(&CommandOnResume, "resume-command", "", "Execute command when the timer is resumed")
+	flag.StringVar(&UUID, "uuid", "", "UUID of the widget")
+	flag.BoolVar(&CommandAsync, "async", false, "Execute the command without waiting it to finish (use together with -command)")
+	flag.BoolVar(&DryRun, "dry-run", false, "Log commands and notifications instead of running or showing them")
+	flag.StringVar(&TextFile, "text-file", "", "Write the current timer to a text file on each change")
+	flag.BoolVar(&TextFileCleanup, "text-file-cleanup", false, "Remove the text file on shutdown (use together with -text-file)")
+	flag.StringVar(&Format, "format", "", "Template for the text sent to BetterTouchTool, with {timer}, {mode}, {state}, {count} and {n} (default {timer})")
+	flag.StringVar(&TextPrefix, "text-prefix", "", "Text prepended to the timer sent to BetterTouchTool")
+	flag.StringVar(&TextSuffix, "text-suffix", "", "Text appended to the timer sent to BetterTouchTool")
+	flag.BoolVar(&AutoAdvance, "auto", false, "Start the next interval automatically when the timer ends")
+	flag.BoolVar(&AutoAdvance, "auto-continue", false, "Same as -auto")
+	flag.BoolVar(&AutoBreak, "auto-break", false, "Start breaks automatically when a work interval ends")
+	flag.BoolVar(&AutoWork, "auto-work", false, "Start work intervals automatically when a break ends")
+	flMinBreak = flag.String("min-break", "", "Refuse to start work until this much of the break has passed, as a duration (e.g. 3m) or a percentage of the break (e.g. 50%)")
+	flag.BoolVar(&Overtime, "overtime", false, "Keep counting up as +MM:SS when a work interval ends, until it is stopped")
+	flag.StringVar(&IconOvertime, "icon-overtime", "", "Icon for work in overtime (default orange)")
+	flag.StringVar(&EyeCommand, "eye-command", "", "Command to run after every -eye-every of running work time, e.g. to look away from the screen")
+	flEyeEvery = flag.String("eye-every", "20m", "Work time between runs of -eye-command")
+	flAlarm = flag.String("alarm", "", "Count down to this time of day like /action/until, e.g. 14:30")
+	flag.StringVar(&AlarmCommand, "alarm-command", "", "Command to run when -alarm or /action/until is reached (default -command)")
+	flag.StringVar(&IconAlarm, "icon-alarm", "", "Icon for the countdown of -alarm or /action/until (default the icon of the mode)")
+	flag.BoolVar(&Strict, "strict", false, "Refuse to pause, stop or skip a running work interval")
+	flag.BoolVar(&Stopwatch, "stopwatch", false, "Count work intervals up until stopped instead of down")
+	flag.BoolVar(&Flowtime, "flowtime", false, "Count work intervals up until stopped, then take a break of -flow-ratio of the work")
+	flag.Float64Var(&FlowRatio, "flow-ratio", 0.2, "Length of a break relative to the work before it, with -flowtime")
+	flag.BoolVar(&CatchUp, "catch-up", false, "After a sleep, skip the intervals that would have ended meanwhile (use together with -auto)")
+	flag.BoolVar(&Notify, "notify", false, "Show a desktop notification at the end of timer (macOS, Linux and Windows)")
+	flag.BoolVar(&RichNotify, "rich-notify", false, "Show a macOS notification with Start/Skip buttons at the end of timer (uses alerter if installed)")
+	flag.IntVar(&Goal, "goal", 0, "Number of work intervals to complete per day, reported in the JSON status")
+	flag.StringVar(&CommandOnGoal, "goal-command", "", "Execute command when the daily goal is reached (use together with -goal)")
+	flag.BoolVar(&RequireAck, "require-ack", false, "When the timer ends, wait for /action/ack before switching mode")
+	flAckReminder = flag.String("ack-reminder", "1m", "Run the end-of-timer command again at this interval until acknowledged (use together with -require-ack)")
+	flWarn = flag.String("warn", "", "Run -warn-command this long before the timer ends (e.g. 1m)")
+	flag.StringVar(&CommandOnWarn, "warn-command", "", "Execute command shortly before the end of timer (use together with -warn)")
+	flag.StringVar(&Token, "token", "", "Require \"Authorization: Bearer TOKEN\" for actions and config changes")
+	flag.BoolVar(&TokenProtectReads, "token-protect-reads", false, "Require the token for read-only endpoints too (use together with -token)")
+	flag.StringVar(&Webhook, "webhook", "", "URL to POST a JSON message to on every mode transition")
+	flag.StringVar(&LabelStopped, "state-stopped", LabelStopped, "Label shown for the stopped state")
+	flag.StringVar(&LabelPaused, "state-paused", LabelPaused, "Label shown for the paused state")
+	flag.StringVar(&LabelRunning, "state-running", LabelRunning, "Label shown for the running state")
+	flag.StringVar(&LabelFinished, "state-finished", LabelFinished, "Label shown for an interval waiting to be acknowledged (use together with -require-ack)")
+	flag.StringVar(&StateFile, "state", "", "Save the timer state to a file and restore it on start")
+	flag.StringVar(&HistoryFile, "history", "", "Append every ended interval to a JSON Lines file")
+	flag.StringVar(&StorageBackend, "storage", "", "Where to keep ended intervals: memory, or jsonl or sqlite with -history as the file (default jsonl with -history, else memory)")
+
+	flDurationWork = flag.String("work", "25m", "Work interval")
+	flDurationShortBreak = flag.String("short", "5m", "Short break interval")
+	flDurationLongBreak = flag.String("long", "15m", "Long break interval")
+	flag.StringVar(&Profile, "profile", "", "Apply the options of a profile from the \"profiles\" of -config")
+	flPreset = flag.String("preset", "", "Take -work, -short, -long and -n from a preset: "+strings.Join(presetNames(), ", ")+" or one from -config")
+	flPort = flag.String("port", "", "BetterTouchTool port")
+	flURL = flag.String("url", "", "URL to post update")
+	flTicker = flag.Int("tick", 100, "Duration in ms for sending updates (default 100)")
+	flHTTPTimeout = flag.String("http-timeout", "200ms", "Timeout for requests to BetterTouchTool")
+	flag.IntVar(&HTTPRetries, "http-retries", 0, "Number of retries for failed requests to BetterTouchTool")
+	flNudge = flag.String("nudge", "", "Resend the timer to BetterTouchTool at this interval even if unchanged (e.g. 30s)")
+	flCheck = flag.Bool("check", false, "Check the options, commands and BetterTouchTool, show what would run and exit")
+	flSelfTest = flag.Bool("self-test", false, "Run through a full cycle in-process, report and exit")
+}
+
 func main() {
 	flag.Usage = func() {
 		fmt.Printf(`Tomato on TouchBar %v (works with BetterTouchTool)
@@ -58,47 +208,126 @@ With options:
 
 Send updates to BetterTouchTool:
    tomato -uuid=UUID -port=12345
-   tomato -icon1=PATH_ICON1 -icon2=PATH_ICON2 -uuid=UUID -url=http://127.0.0.1:12345/update_touch_bar_widget/
+   tomato -icon1=PATH_ICON1 -icon2=PATH_ICON2 -icon3=PATH_ICON3 -uuid=UUID -url=http://127.0.0.1:12345/update_touch_bar_widget/
+   tomato -uuid=UUID -port=12345 -text-prefix="🍅 "
+   tomato -uuid=UUID -port=12345 -format="{timer} {count}/{n}"
+
+Write the timer to a text file (e.g. for OBS):
+   tomato -text-file=/tmp/tomato.txt
 
 Execute a command at the end of timer:
    tomato -command="terminal-notifier -title Pomodoro -message \"Hey, time is over\!\" -sound default"
 
+Control a running tomato (start, stop, pause, resume, skip, toggle, ack or status):
+   tomato start
+   tomato -connect=127.0.0.1:12321 status
+
+Write a config file with every option to start from (- for stdout):
+   tomato -work=50m config init [FILE]
+
+Every option can also be set by a TOMATO_* environment variable, e.g.
+TOMATO_WORK=50m for -work or TOMATO_START_COMMAND for -start-command,
+or in a JSON or TOML file given by -config, e.g. {"work": "50m", "n": 3}.
+Options given on the command line take precedence over the environment,
+which takes precedence over -preset, which takes precedence over the file.
+
 Options:
 `, version)
 		flag.PrintDefaults()
 	}
 
-	flListen := flag.String("listen", ":12321", "Address to listen on")
+	flag.Parse()
+	if err := setFlagsFromEnv(setFlags()); err != nil {
+		fatalf("%v", err)
+	}
+	fixedFlags = setFlags()
+	if *flConfig == "" && !fixedFlags["config"] {
+		*flConfig = findConfig()
+	}
+	if *flConfig != "" {
+		if err := setFlagsFromConfig(*flConfig); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	if *flPreset != "" {
+		if err := setFlagsFromPreset(*flPreset); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	base := currentOptions()
+	if Profile != "" {
+		o, err := profileOptions(Profiles, base, Profile)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		o.set()
+	}
 
-	flag.IntVar(&N, "n", N, "Number of intervals between long break")
-	flag.StringVar(&SepColon, "colon", SepColon, "Custom separator")
-	flag.StringVar(&SepBreak, "colon-alt", SepBreak, "Alternative separator for break modes")
-	flag.StringVar(&Icon1, "icon1", "", "Icon for work (default red)")
-	flag.StringVar(&Icon2, "icon2", "", "Icon for break session (default green)")
-	flag.StringVar(&Command, "command", "", "Execute command at the end of timer")
-	flag.StringVar(&CommandOnStart, "start-command", "", "Execute command on start of timer")
-	flag.StringVar(&UUID, "uuid", "", "UUID of the widget")
-	flag.BoolVar(&CommandAsync, "async", false, "Execute the command without waiting it to finish (use together with -command)")
+	if flag.Arg(0) == "config" {
+		runConfig(flag.Args()[1:])
+	}
+	if flag.NArg() > 0 {
+		addr := *flConnect
+		if addr == "" {
+			addr = *flListen
+		}
+		runClient(addr, flag.Args())
+	}
 
-	flDurationWork := flag.String("work", "25m", "Work interval")
-	flDurationShortBreak := flag.String("short", "5m", "Short break interval")
-	flDurationLongBreak := flag.String("long", "15m", "Long break interval")
-	flPort := flag.String("port", "", "BetterTouchTool port")
-	flURL := flag.String("url", "", "URL to post update")
-	flTicker := flag.Int("tick", 100, "Duration in ms for sending updates (default 100)")
+	if err := setDataFiles(setFlags()); err != nil {
+		log.Printf("Warning: the state and history are not kept: %v", err)
+	}
+	st, err := currentOptions().parse()
+	if err != nil {
+		fatalf("%v", err)
+	}
+	st.apply()
+	if err := checkProfiles(Profiles, base); err != nil {
+		fatalf("%v", err)
+	}
+	if EyeCommand != "" {
+		log.Printf("Command to run after every %v of work: %q", EyeEvery, EyeCommand)
+	}
+	if *flQuiet != "" {
+		var err error
+		if Quiet, err = parseQuiet(*flQuiet); err != nil {
+			fatalf("%v", err)
+		}
+		log.Printf("Quiet hours: %v", quietHours())
+	}
+	if *flCycle != "" {
+		var err error
+		if Cycle, err = parseCycle(*flCycle); err != nil {
+			fatalf("Invalid cycle: %v", err)
+		}
+		N = cycleWorks()
+		log.Printf("Cycle: %v", formatCycle())
+	}
+	log.Printf("Interval=%v ShortBreak=%v LongBreak=%v N=%v", DurationWork, DurationShortBreak, DurationLongBreak, N)
+	if err := checkPresets(); err != nil {
+		fatalf("%v", err)
+	}
+	if len(Presets) > 0 {
+		log.Printf("Presets: %v", strings.Join(presetNames(), ", "))
+	}
 
-	flag.Parse()
+	if *flSelfTest {
+		if !selfTest() {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
-	if *flTicker <= 10 || *flTicker >= 1000 {
-		fatalf("Invalid ticker value (must between 10 and 1000)")
+	httpClient.Timeout = mustParseDuration(*flHTTPTimeout)
+	if Goal < 0 {
+		fatalf("Invalid goal (%v)", Goal)
 	}
-	if N <= 0 || N >= 10 {
-		fatalf("Invalid number of intervals (%v)", N)
+	if FlowRatio <= 0 {
+		fatalf("Invalid flow ratio (%v)", FlowRatio)
+	}
+	if HTTPRetries < 0 {
+		fatalf("Invalid number of retries (%v)", HTTPRetries)
 	}
-	DurationWork = parseDuration(*flDurationWork)
-	DurationShortBreak = parseDuration(*flDurationShortBreak)
-	DurationLongBreak = parseDuration(*flDurationLongBreak)
-	log.Printf("Interval=%v ShortBreak=%v LongBreak=%v N=%v", DurationWork, DurationShortBreak, DurationLongBreak, N)
 
 	switch {
 	case *flURL != "" && *flPort != "":
@@ -110,44 +339,176 @@ Options:
 		if _, err := url.Parse(URL); err != nil {
 			fatalf("Unable to parse url: %v", err)
 		}
-		log.Println("Send update every %vms to URL: %v", *flTicker, URL)
+		log.Printf("Send update every %vms to URL: %v", *flTicker, URL)
 	case *flPort != "":
 		URL = fmt.Sprintf("http://127.0.0.1:%v/update_touch_bar_widget/", *flPort)
 		log.Printf("Send update every %vms to BetterTouchTool running at :%v with uuid=%v", *flTicker, *flPort, UUID)
 	}
 
+	if URL == "" && (Icon1 != "" || Icon2 != "" || Icon3 != "") {
+		log.Printf("Warning: -icon1/-icon2/-icon3 are ignored without -url or -port")
+	}
+	if err := checkStorage(); err != nil {
+		fatalf("%v", err)
+	}
+
+	s := NewServer()
+	s.base = base
+	if *flAlarm != "" {
+		var err error
+		if s.until, err = nextClockTime(*flAlarm, timeNow()); err != nil {
+			fatalf("%v", err)
+		}
+		log.Printf("Alarm at %v", s.until.Format("Mon Jan 2 15:04:05"))
+	}
 	if URL != "" {
-		Icon1Data = mustLoadIcon(Icon1, "red.png")
-		Icon2Data = mustLoadIcon(Icon2, "green.png")
-		err := doRequest(formatTimer(DurationWork, SepColon), Icon1Data)
+		icons, err := loadIcons(currentOptions())
 		if err != nil {
+			fatalf("%v", err)
+		}
+		icons.apply()
+		if err := doRequest(requestCtx, nextUpdate(), s.widgetText(s.formatTimer()), s.icon()); err != nil {
 			fatalf("Error while sending request to %v: %v", URL, err)
 		}
 	}
 
-	s := NewServer()
-	go func() {
-		ticker := time.NewTicker(time.Duration(*flTicker) * time.Millisecond)
-		for _ = range ticker.C {
-			s.RefreshStatus(false)
+	if TextFile != "" {
+		log.Printf("Write timer to text file: %v", TextFile)
+	}
+	if StateFile != "" {
+		log.Printf("Save timer state to: %v", StateFile)
+	}
+	if RichNotify && runtime.GOOS != "darwin" {
+		log.Printf("Warning: -rich-notify is only supported on macOS")
+	}
+	var startDays map[time.Weekday]bool
+	if *flStartAt != "" {
+		var err error
+		if startDays, err = parseWeekdayList(*flStartDays); err != nil {
+			fatalf("%v", err)
 		}
-	}()
+		next, err := nextStart(*flStartAt, startDays, timeNow())
+		if err != nil {
+			fatalf("%v", err)
+		}
+		log.Printf("Start work at %v on %v, next on %v", *flStartAt, *flStartDays, next.Format("Mon Jan 2 15:04"))
+	}
+	var nudge time.Duration
+	if *flNudge != "" && URL != "" {
+		nudge = mustParseDuration(*flNudge)
+		log.Printf("Resend update to BetterTouchTool every %v", nudge)
+	}
 
+	async := ""
+	if CommandAsync {
+		async = " (without waiting it to finish)"
+	}
 	if Command != "" {
-		async := ""
-		if CommandAsync {
-			async = " (without waiting it to finish)"
-		}
 		log.Printf("Command to run at the end of timer%v: %q\n", async, Command)
 	}
+	for _, mode := range []Mode{ModeWork, ModeShortBreak, ModeLongBreak} {
+		if command := endCommand(mode); command != Command {
+			log.Printf("Command to run at the end of %v%v: %q\n", mode, async, command)
+		}
+	}
+	if Quiet != nil && QuietCommand != "" {
+		log.Printf("Command to run at the end of timer in quiet hours%v: %q\n", async, QuietCommand)
+	}
+	if *flCheck {
+		if err := checkCommands(); err != nil {
+			fatalf("%v", err)
+		}
+		if err := checkWebhook(); err != nil {
+			fatalf("%v", err)
+		}
+		fmt.Printf("Configuration OK, would listen at %v\n", *flListen)
+		os.Exit(0)
+	}
+
+	done := make(chan struct{})
+	tick := Tick
+	go func() {
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case d := <-s.retick:
+				ticker.Reset(d)
+			case <-ticker.C:
+				s.RefreshStatus(false)
+			}
+		}
+	}()
+	if *flStartAt != "" {
+		go s.startDaily(*flStartAt, startDays, done)
+	}
+	if nudge > 0 {
+		go func() {
+			ticker := time.NewTicker(nudge)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					s.Nudge()
+				}
+			}
+		}()
+	}
+
+	srv := &http.Server{Addr: *flListen, Handler: s.Handler()}
+	srv.RegisterOnShutdown(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.closeStreams()
+	})
+	stopped := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	if *flConfig != "" {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				s.Reload(*flConfig)
+			}
+		}()
+	}
+	go func() {
+		<-sig
+		log.Printf("Shutting down")
+		close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("Error while shutting down: %v", err)
+		}
+		s.Close()
+		cancelRequests()
+		s.requests.Wait()
+		if TextFile != "" && TextFileCleanup {
+			os.Remove(TextFile)
+		}
+		close(stopped)
+	}()
+
 	log.Printf("Server listen at %v", *flListen)
-	err := http.ListenAndServe(*flListen, s.Handler())
-	log.Fatal(err)
+	err = srv.ListenAndServe()
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+	<-stopped
 }
 
 type Mode string
 
 func (mode Mode) Duration() time.Duration {
+	if d, ok := durationAt(mode, timeNow()); ok {
+		return d
+	}
 	switch mode {
 	case ModeWork:
 		return DurationWork
@@ -159,6 +520,14 @@ func (mode Mode) Duration() time.Duration {
 	panic("unexpected")
 }
 
+func (mode Mode) Valid() bool {
+	switch mode {
+	case ModeWork, ModeShortBreak, ModeLongBreak:
+		return true
+	}
+	return false
+}
+
 func (mode Mode) Sep() string {
 	switch mode {
 	case ModeWork:
@@ -169,32 +538,209 @@ func (mode Mode) Sep() string {
 	panic("unexpected")
 }
 
+// Server holds the timer. Action handlers hold mu for their whole run, so
+// concurrent actions apply one after another and each response reflects the
+// state right after its own action.
 type Server struct {
+	// mu guards all fields below. Exported methods acquire it, unexported
+	// methods expect the caller to hold it.
+	mu sync.Mutex
+
 	mode  Mode
 	state string
-	t     time.Time
-	d     time.Duration // remaining duration
-	count int
+	t     time.Time     // end time, or start time for a stopwatch
+	d     time.Duration // remaining duration, or elapsed for a stopwatch
+	began time.Time     // start of the current interval, for the history
+	count int           // completed work intervals shown in the status
+	extra int           // work intervals added to the current cycle by /action/extend
+
+	// workSinceLongBreak counts work intervals since the last finished long
+	// break and decides when the next long break is due.
+	workSinceLongBreak int
+
+	warned    bool          // -warn-command has run for the current interval
+	flowBreak time.Duration // length of the current break with -flowtime
+	once      time.Duration // length of the current interval from /action/start?duration=
+	planned   time.Duration // length of the current interval, fixed when it starts
+	paused    time.Duration // time the current interval was paused before pausedAt
+	pausedAt  time.Time     // start of the current pause, for the history
+	overtime  bool          // the running work interval has ended, see -overtime
+	restUntil time.Time     // earliest start of the next work interval, see -min-break
+	reminded  time.Time     // last reminder of an interval waiting for /action/ack
+
+	undo     *snapshot // position before the last action that changed it
+	finished int       // intervals completed so far, which can not be undone
+
+	watch watch     // ad-hoc stopwatch shown instead of the timer while it runs
+	until time.Time // end of the countdown of ActionUntil, if any
+
+	scheduled []scheduledStart // pending starts of Schedule, soonest first
+
+	reminders    []*reminder   // recurring commands of Reminders
+	eyeWork      time.Duration // work time of ended intervals since the last break, see -eye-command
+	eyeRun       time.Duration // work time at the last run of -eye-command
+	lastReminder int           // ID of the last reminder added
+
+	seq        int64  // incremented on every change of the rendered status
+	lastStatus string // last rendered status, used to detect changes
+
+	shown      shownStatus // what the last output showed
+	shownTimer string      // timer of the last output
+
+	saved      savedState // last state written to StateFile
+	statsDirty bool       // completed changed since the last write
+
+	completed map[string]int // completed work intervals by local date
+	storage   Storage        // ended intervals, see -storage
+	metrics   metrics        // counters for /metrics
+
+	subscribers map[chan statusEvent]struct{} // clients of /events
+	closed      chan struct{}                 // closed by Close
+	retick      chan time.Duration            // new Tick for the ticker, see PATCH /config
+	base        options                       // options before the profile, see Reload
+	requests    sync.WaitGroup                // requests to BetterTouchTool and -webhook in flight
+
+	queue   []queuedCommand // commands for runCommands
+	queued  chan struct{}   // wakes up runCommands
+	running sync.WaitGroup  // commands queued or running
+
+	startedAt time.Time
+}
+
+// shownStatus is everything the rendered status depends on, with the timer
+// truncated to whole seconds.
+type shownStatus struct {
+	mode     Mode
+	state    string
+	overtime bool
+	timer    time.Duration
+	watch    time.Duration
+	until    time.Duration
+	count, n int
+}
+
+type statusEvent struct {
+	seq  int64
+	data []byte
 }
 
 func NewServer() *Server {
-	return &Server{
-		mode:  ModeWork,
-		state: StateStopped,
+	s := &Server{
+		mode:      ModeWork,
+		state:     StateStopped,
+		completed: make(map[string]int),
+
+		subscribers: make(map[chan statusEvent]struct{}),
+		closed:      make(chan struct{}),
+		retick:      make(chan time.Duration, 1),
+		queued:      make(chan struct{}, 1),
+		base:        currentOptions(),
+
+		startedAt: timeNow(),
+	}
+	if StateFile != "" {
+		s.loadState()
+	}
+	s.storage = newStorage()
+	go s.runCommands()
+	return s
+}
+
+// Close ends all event streams and saves the state to StateFile and the
+// history to its Storage. It is
+// called after the HTTP server has shut down.
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeStreams()
+	s.refreshStatus(false)
+	if err := s.storage.Close(); err != nil {
+		log.Printf("Error while writing history: %v", err)
 	}
 }
 
+// closeStreams ends all event streams, so that their connections do not keep
+// the HTTP server from shutting down.
+func (s *Server) closeStreams() {
+	select {
+	case <-s.closed:
+	default:
+		close(s.closed)
+	}
+}
+
+// Handler returns the HTTP handler of the server, requiring -token when set.
 func (s *Server) Handler() http.Handler {
+	return requireToken(s.mux())
+}
+
+func (s *Server) mux() *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.Index)
 	mux.HandleFunc("/status", s.Status)
 	mux.HandleFunc("/time", s.Time)
 	mux.HandleFunc("/action/start", s.ActionStart)
 	mux.HandleFunc("/action/stop", s.ActionStop)
+	mux.HandleFunc("/action/toggle", s.ActionToggle)
+	mux.HandleFunc("/action/pause", s.ActionPause)
+	mux.HandleFunc("/action/resume", s.ActionResume)
+	mux.HandleFunc("/action/reset", s.ActionReset)
+	mux.HandleFunc("/action/restart", s.ActionRestart)
+	mux.HandleFunc("/action/ack", s.ActionAck)
+	mux.HandleFunc("/action/skip", s.ActionSkip)
+	mux.HandleFunc("/action/extend", s.ActionExtend)
+	mux.HandleFunc("/action/add", s.ActionAdd)
+	mux.HandleFunc("/action/mode", s.ActionSetMode)
+	mux.HandleFunc("/action/set", s.ActionSet)
+	mux.HandleFunc("/action/cycle", s.ActionCycle)
+	mux.HandleFunc("/action/undo", s.ActionUndo)
+	mux.HandleFunc("/action/stopwatch/start", s.ActionStopwatchStart)
+	mux.HandleFunc("/action/stopwatch/stop", s.ActionStopwatchStop)
+	mux.HandleFunc("/action/stopwatch/lap", s.ActionStopwatchLap)
+	mux.HandleFunc("/action/until", s.ActionUntil)
+	mux.HandleFunc("/schedule", s.Schedule)
+	mux.HandleFunc("/reminders", s.Reminders)
+	mux.HandleFunc("/reminders/", s.Reminders)
+	mux.HandleFunc("/config", s.Config)
+	mux.HandleFunc("/config/schedule", s.ConfigSchedule)
+	mux.HandleFunc("/preset/", s.Preset)
+	mux.HandleFunc("/profile/", s.Profile)
+	mux.HandleFunc("/stats", s.Stats)
+	mux.HandleFunc("/history", s.History)
+	mux.HandleFunc("/history/stats", s.HistoryStats)
+	mux.HandleFunc("/events", s.Events)
+	mux.HandleFunc("/ws", s.WebSocket)
+	mux.HandleFunc("/healthz", s.Healthz)
+	mux.HandleFunc("/metrics", s.Metrics)
 
 	return mux
 }
 
+// requireToken rejects requests without "Authorization: Bearer <Token>".
+// Only requests that may change state are checked, unless TokenProtectReads
+// is set.
+func requireToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Token == "" || (r.Method == "GET" || r.Method == "HEAD") && !TokenProtectReads {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if !hasToken(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// hasToken reports whether r carries "Authorization: Bearer <Token>".
+func hasToken(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	return subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+Token)) == 1
+}
+
 func (s *Server) Index(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		http.NotFound(w, r)
@@ -210,7 +756,10 @@ func (s *Server) Status(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := s.RefreshStatus(true)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str := s.refreshStatus(true)
 	if r.Header.Get("Accept") == "application/json" {
 		w.Write(s.formatStatusJSON())
 	} else {
@@ -218,232 +767,1799 @@ func (s *Server) Status(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Healthz reports that the server is alive. Unlike Status, it does not
+// refresh the timer or send updates.
+func (s *Server) Healthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"version": version,
+		"state":   stateLabel(s.state),
+		"uptime":  int(timeNow().Sub(s.startedAt) / time.Second),
+	})
+	w.Write(data)
+}
+
+// Stats reports the number of work intervals that ran to completion.
+func (s *Server) Stats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, n := range s.completed {
+		total += n
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"today": s.completed[dateKey(timeNow())],
+		"total": total,
+		"byDay": s.completed,
+	})
+	w.Write(data)
+}
+
+// Events streams the status JSON as Server-Sent Events whenever it changes.
+func (s *Server) Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan statusEvent, 1)
+	s.mu.Lock()
+	s.refreshStatus(false)
+	ch <- statusEvent{s.seq, s.formatStatusJSON()}
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	var last int64 = -1
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.closed:
+			return
+		case ev := <-ch:
+			if ev.seq <= last {
+				continue
+			}
+			last = ev.seq
+			fmt.Fprintf(w, "data: %s\n\n", ev.data)
+			flusher.Flush()
+		}
+	}
+}
+
+// publish sends the current status to all /events subscribers. Slow
+// subscribers only receive the latest status.
+func (s *Server) publish() {
+	if len(s.subscribers) == 0 {
+		return
+	}
+	ev := statusEvent{s.seq, s.formatStatusJSON()}
+	for ch := range s.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- ev
+		}
+	}
+}
+
 func (s *Server) Time(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		http.NotFound(w, r)
 		return
 	}
 
-	str := s.RefreshStatus(true)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str := s.refreshStatus(true)
 	fmt.Fprint(w, str)
 }
 
-// ActionStart starts or pauses the current interval.
+// ActionStart starts or pauses the current interval. When starting, the
+// duration parameter, e.g. duration=40m, replaces the length of this interval
+// only.
 func (s *Server) ActionStart(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.NotFound(w, r)
 		return
 	}
+	var once time.Duration
+	if str := r.FormValue("duration"); str != "" {
+		var err error
+		if once, err = parseDuration(str); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
-	now := time.Now()
-	switch s.state {
-	case StateStopped:
-		t := now.Add(s.mode.Duration())
-		s.t = t
-		s.state = StateRunning
-		s.executeCommandOnStart()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
 
-	case StatePaused:
-		t := now.Add(s.d)
-		s.t = t
-		s.state = StateRunning
-		s.executeCommandOnStart()
+	if s.stateChanged(w, r) {
+		return
+	}
 
-	case StateRunning:
-		s.RefreshStatus(true)
-		if s.state == StateRunning {
-			s.d = s.t.Sub(now)
-			s.state = StatePaused
+	if s.strict(w) {
+		return
+	}
+	if s.resting(w) {
+		return
+	}
+	if once > 0 {
+		if s.state != StateStopped || s.stopwatch() {
+			http.Error(w, "A duration can only be given when starting a stopped timer", http.StatusConflict)
+			return
 		}
+		s.once = once
 	}
 
+	s.toggle()
+	s.saveState()
 	str := s.formatTimer()
 	fmt.Fprint(w, str)
 }
 
-// ActionStop stops the current running interval or switch mode.
-func (s *Server) ActionStop(w http.ResponseWriter, r *http.Request) {
+// ActionToggle starts or pauses the current interval like ActionStart, and
+// responds with the new state instead of the timer.
+func (s *Server) ActionToggle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.NotFound(w, r)
 		return
 	}
 
-	switch s.state {
-	case StateRunning, StatePaused:
-		s.state = StateStopped
-	case StateStopped:
-		switch s.mode {
-		case ModeWork:
-			if s.count < N {
-				s.mode = ModeShortBreak
-			} else {
-				s.mode = ModeLongBreak
-			}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
 
-		case ModeShortBreak:
-			s.mode = ModeWork
+	if s.stateChanged(w, r) {
+		return
+	}
 
-		case ModeLongBreak:
-			s.mode = ModeShortBreak
-		}
+	if s.strict(w) {
+		return
+	}
+	if s.resting(w) {
+		return
 	}
 
-	str := s.RefreshStatus(true)
-	fmt.Fprint(w, str)
+	s.toggle()
+	s.saveState()
+	fmt.Fprint(w, stateLabel(s.state))
 }
 
-func (s *Server) nextMode() {
-	switch s.mode {
-	case ModeShortBreak, ModeLongBreak:
-		if s.mode == ModeLongBreak {
-			s.count = 0
+func (s *Server) toggle() {
+	switch s.state {
+	case StateStopped:
+		s.began = timeNow()
+		s.planned = s.duration()
+		s.t = s.began
+		if !s.stopwatch() {
+			s.t = s.t.Add(s.duration())
 		}
+		s.state = StateRunning
+		s.warned = false
+		s.runCommand(CommandOnStart)
 
-		s.mode = ModeWork
+	case StatePaused:
+		s.resume()
 
-	case ModeWork:
-		s.count++
-		if s.count < N {
-			s.mode = ModeShortBreak
-		} else {
-			s.mode = ModeLongBreak
-		}
+	case StateRunning:
+		s.pause()
 
-	default:
-		panic("unexpected")
+	case StateFinished:
+		s.ack()
+		if s.state == StateStopped {
+			s.toggle()
+		}
 	}
 }
 
-func (s *Server) executeCommand() {
-	if Command == "" {
+// ActionPause pauses a running interval and does nothing otherwise.
+func (s *Server) ActionPause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
 		return
 	}
 
-	cmd := exec.Command("/bin/sh", "-c", Command)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
 
-	var err error
-	if CommandAsync {
-		log.Println("Executing command (without waiting it to finish)...")
-		err = cmd.Start()
-		if err == nil {
-			go func() {
-				err2 := cmd.Wait()
-				if err2 != nil {
-					printCommandError(err)
-				} else {
-					log.Println("Command executed")
-				}
-			}()
-		}
-	} else {
-		err = cmd.Run()
-		if err == nil {
-			log.Println("Command executed")
-		}
+	if s.stateChanged(w, r) {
+		return
 	}
-	if err != nil {
-		printCommandError(err)
+
+	if s.strict(w) {
+		return
+	}
+
+	if s.state == StateRunning {
+		s.pause()
 	}
+
+	s.saveState()
+	str := s.formatTimer()
+	fmt.Fprint(w, str)
 }
 
-func (s *Server) executeCommandOnStart() {
-	if CommandOnStart == "" {
+// ActionResume resumes a paused interval and does nothing otherwise.
+func (s *Server) ActionResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
 		return
 	}
 
-	cmd := exec.Command("/bin/sh", "-c", CommandOnStart)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
 
-	var err error
-	if CommandAsync {
-		log.Println("Executing command (without waiting it to finish)...")
-		err = cmd.Start()
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if s.state == StatePaused {
+		s.resume()
+	}
+
+	s.saveState()
+	str := s.formatTimer()
+	fmt.Fprint(w, str)
+}
+
+// pause pauses the running interval, unless it has just ended or is in
+// overtime.
+func (s *Server) pause() {
+	s.refreshStatus(true)
+	if s.state == StateRunning && !s.overtime {
+		s.d = s.t.Sub(timeNow())
+		if s.stopwatch() {
+			s.d = -s.d
+		}
+		s.state = StatePaused
+		s.pausedAt = timeNow()
+		s.runCommand(CommandOnPause)
+	}
+}
+
+func (s *Server) resume() {
+	if !s.pausedAt.IsZero() {
+		s.paused += timeNow().Sub(s.pausedAt)
+		s.pausedAt = time.Time{}
+	}
+	s.t = timeNow().Add(s.d)
+	if s.stopwatch() {
+		s.t = timeNow().Add(-s.d)
+	}
+	s.state = StateRunning
+	s.runCommand(CommandOnStart)
+	s.runCommand(CommandOnResume)
+}
+
+// ActionStop stops the current running interval or switch mode. Switching
+// mode follows nextMode, so it counts toward the long break like a finished
+// interval.
+func (s *Server) ActionStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if s.strict(w) {
+		return
+	}
+
+	prevMode := s.mode
+	switch {
+	case s.state == StateFinished:
+		s.ack()
+	case s.inOvertime():
+		// The end command has run when the overtime began.
+		s.finish(timeNow())
+		s.state = StateStopped
+	case s.state != StateStopped && s.stopwatch():
+		// A stopwatch only ends here, so it counts as finished.
+		elapsed := s.elapsed()
+		s.finish(timeNow())
+		s.state = StateStopped
+		if Flowtime {
+			s.setFlowBreak(time.Duration(float64(elapsed) * FlowRatio))
+		}
+		s.intervalEnded(prevMode)
+	case s.state == StateRunning, s.state == StatePaused:
+		s.endInterval(OutcomeStopped, timeNow())
+		s.state = StateStopped
+	case s.state == StateStopped:
+		// Switch mode the same way as when the timer ends.
+		s.nextMode()
+	}
+
+	str := s.refreshStatus(true)
+	if r.Header.Get("Accept") == "application/json" {
+		data, _ := json.Marshal(map[string]interface{}{
+			"previous_mode": prevMode,
+			"new_mode":      s.mode,
+			"new_state":     stateLabel(s.state),
+			"timer":         str,
+		})
+		w.Write(data)
+	} else {
+		fmt.Fprint(w, str)
+	}
+}
+
+// ActionReset stops the current interval so it can be started again from its
+// full duration. Mode and count are left untouched, unless cycle=1 resets
+// the whole cycle to the first work interval.
+func (s *Server) ActionReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+	cycle, _ := strconv.ParseBool(r.FormValue("cycle"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if s.strict(w) {
+		return
+	}
+
+	if s.state != StateStopped {
+		s.endInterval(OutcomeStopped, timeNow())
+	}
+	s.state = StateStopped
+	s.d = 0
+	if cycle {
+		s.mode = ModeWork
+		s.count = 0
+		s.workSinceLongBreak = 0
+		s.extra = 0
+		s.restUntil = time.Time{}
+	}
+
+	str := s.refreshStatus(true)
+	fmt.Fprint(w, str)
+}
+
+// ActionAck acknowledges an interval that ended with -require-ack and
+// switches to the next mode. It does nothing otherwise.
+func (s *Server) ActionAck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if s.strict(w) {
+		return
+	}
+
+	if s.state == StateFinished {
+		s.ack()
+	}
+
+	fmt.Fprint(w, s.refreshStatus(true))
+}
+
+// ActionRestart starts the current interval over from its full duration.
+// A running timer keeps running and a paused one stays paused. Mode and
+// count are left untouched.
+func (s *Server) ActionRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if s.strict(w) {
+		return
+	}
+
+	if s.state != StateStopped {
+		now := timeNow()
+		s.endInterval(OutcomeStopped, now)
+		s.planned = s.duration()
+		if s.state == StateFinished {
+			s.state = StateRunning
+		}
+		switch {
+		case s.state == StatePaused && s.stopwatch():
+			s.d = 0
+		case s.state == StatePaused:
+			s.d = s.duration()
+		case s.stopwatch():
+			s.t = now
+		default:
+			s.t = now.Add(s.duration())
+		}
+	}
+
+	fmt.Fprint(w, s.refreshStatus(true))
+}
+
+// ActionSkip advances to the next mode as if the current interval had
+// finished. The end-of-timer command only runs with notify=1.
+func (s *Server) ActionSkip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+	notify, _ := strconv.ParseBool(r.FormValue("notify"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if s.strict(w) {
+		return
+	}
+
+	if s.state == StateFinished {
+		// The interval is over already; skipping only acknowledges it.
+		s.ack()
+		fmt.Fprint(w, s.refreshStatus(true))
+		return
+	}
+	if s.inOvertime() {
+		// The interval is over already; skipping only records the overtime.
+		s.finish(timeNow())
+		s.state = StateStopped
+		fmt.Fprint(w, s.refreshStatus(true))
+		return
+	}
+	if s.state != StateStopped {
+		s.endInterval(OutcomeSkipped, timeNow())
+	}
+	finished := s.mode
+	s.nextMode()
+	s.state = StateStopped
+	s.d = 0
+	if notify {
+		s.intervalEnded(finished)
+	}
+
+	str := s.refreshStatus(true)
+	fmt.Fprint(w, str)
+}
+
+// ActionExtend adds one work interval before the next long break. N is
+// restored once the long break ends. With the d parameter, e.g. d=5m or
+// d=-5m, it changes the time of the current interval instead, like
+// ActionAdd. Other parameters are rejected, so a misspelled d does not add
+// a work interval.
+func (s *Server) ActionExtend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := r.Form["d"]; ok {
+		s.ActionAdd(w, r)
+		return
+	}
+	for name := range r.Form {
+		if name != "if" {
+			http.Error(w, fmt.Sprintf("Unknown parameter %q for /action/extend (d changes the time of the current interval)", name), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if len(Cycle) > 0 {
+		http.Error(w, "Work intervals can not be added to a -cycle", http.StatusConflict)
+		return
+	}
+	s.extra++
+	// A long break that has not started yet becomes a short break.
+	if s.mode == ModeLongBreak && s.state == StateStopped && !s.longBreakDue() {
+		s.mode = ModeShortBreak
+	}
+	s.refreshStatus(true)
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"n":           N,
+		"effective_n": s.n(),
+	})
+	w.Write(data)
+}
+
+// ActionAdd adds the duration given by the d parameter (e.g. 5m, or -5m to
+// subtract) to a running or paused interval. The remaining time never drops
+// below zero.
+func (s *Server) ActionAdd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	str := r.FormValue("d")
+	sign := time.Duration(1)
+	if strings.HasPrefix(str, "-") {
+		sign, str = -1, str[1:]
+	}
+	d, err := parseDuration(str)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	d *= sign
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if d < 0 && s.strict(w) {
+		return
+	}
+
+	switch s.state {
+	case StateRunning:
+		now := timeNow()
+		if s.stopwatch() {
+			// The start moves back to add elapsed time.
+			s.t = s.t.Add(-d)
+			if s.t.After(now) {
+				s.t = now
+			}
+			break
+		}
+		s.t = s.t.Add(d)
+		if s.overtime {
+			// Enough added time leaves the overtime; the end command runs
+			// again when the timer runs out.
+			s.overtime = !s.t.After(now)
+			break
+		}
+		if s.t.Before(now) {
+			s.t = now
+		}
+	case StatePaused:
+		s.d += d
+		if s.d < 0 {
+			s.d = 0
+		}
+	}
+
+	fmt.Fprint(w, s.refreshStatus(true))
+}
+
+// ActionSetMode switches to the mode given by the mode parameter and stops
+// the timer. The count is left unchanged.
+func (s *Server) ActionSetMode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	mode := Mode(r.FormValue("mode"))
+	if !mode.Valid() {
+		http.Error(w, fmt.Sprintf("Invalid mode %q", mode), http.StatusBadRequest)
+		return
+	}
+	if !inCycle(mode) {
+		http.Error(w, notInCycle(mode), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if s.strict(w) {
+		return
+	}
+
+	if s.state != StateStopped {
+		s.endInterval(OutcomeStopped, timeNow())
+	}
+	s.mode = mode
+	s.flowBreak = 0
+	s.state = StateStopped
+	s.d = 0
+
+	fmt.Fprint(w, s.refreshStatus(true))
+}
+
+// ActionSet moves the timer to a given position of the cycle. All parameters
+// are optional: mode switches mode and stops the timer, count sets the
+// completed work intervals shown in the status without moving the next long
+// break, and remaining sets the time left, leaving a
+// running timer running and pausing it otherwise. For a stopwatch,
+// remaining sets the elapsed time.
+func (s *Server) ActionSet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var (
+		mode      Mode
+		count     = -1
+		remaining time.Duration
+		err       error
+	)
+	if str := r.FormValue("mode"); str != "" {
+		mode = Mode(str)
+		if !mode.Valid() {
+			http.Error(w, fmt.Sprintf("Invalid mode %q", mode), http.StatusBadRequest)
+			return
+		}
+		if !inCycle(mode) {
+			http.Error(w, notInCycle(mode), http.StatusBadRequest)
+			return
+		}
+	}
+	if str := r.FormValue("count"); str != "" {
+		if count, err = strconv.Atoi(str); err != nil || count < 0 {
+			http.Error(w, fmt.Sprintf("Invalid count %q", str), http.StatusBadRequest)
+			return
+		}
+	}
+	if str := r.FormValue("remaining"); str != "" {
+		if remaining, err = parseDuration(str); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if count >= 0 && !s.validCount(count) {
+		http.Error(w, invalidCount(count, s.n()), http.StatusBadRequest)
+		return
+	}
+	if (mode != "" && mode != s.mode || remaining > 0) && s.strict(w) {
+		return
+	}
+	if s.state == StateFinished && (mode != "" && mode != s.mode || remaining > 0) {
+		// The interval is over already: it counts as completed, like with
+		// /action/ack, and the rest applies to the next one.
+		s.finish(s.t)
+		s.state = StateStopped
+	}
+
+	if mode != "" && mode != s.mode {
+		if s.state != StateStopped {
+			s.endInterval(OutcomeStopped, timeNow())
+		}
+		s.mode = mode
+		s.flowBreak = 0
+		s.state = StateStopped
+		s.d = 0
+	}
+	if count >= 0 {
+		s.count = count
+	}
+	if remaining > 0 {
+		now := timeNow()
+		switch {
+		case s.state != StateRunning:
+			if s.state == StateStopped {
+				s.began = now
+				s.planned = s.duration()
+			}
+			s.d = remaining
+			s.state = StatePaused
+		case s.stopwatch():
+			s.t = now.Add(-remaining)
+		default:
+			s.t = now.Add(remaining)
+			s.overtime = false
+		}
+	}
+
+	fmt.Fprint(w, s.refreshStatus(true))
+}
+
+// ActionCycle sets the number of work intervals since the last long break to
+// the i parameter, e.g. after a restart, so the long break comes at the right
+// time. i must be between 0 and N. The count shown in the status is left as
+// it is; see ActionSet.
+func (s *Server) ActionCycle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	str := r.FormValue("i")
+	i, err := strconv.Atoi(str)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid count %q", str), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if s.strict(w) {
+		return
+	}
+
+	if !s.validCount(i) {
+		http.Error(w, invalidCount(i, s.n()), http.StatusBadRequest)
+		return
+	}
+	s.workSinceLongBreak = i
+
+	fmt.Fprint(w, s.refreshStatus(true))
+}
+
+// validCount reports whether i work intervals fit in the current cycle.
+// Without long breaks, any number does.
+func (s *Server) validCount(i int) bool {
+	return i >= 0 && (s.n() == 0 || i <= s.n())
+}
+
+func invalidCount(i, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("Invalid count %v (must be at least 0)", i)
+	}
+	return fmt.Sprintf("Invalid count %v (must be between 0 and %v)", i, n)
+}
+
+// ActionUndo reverts the last action that changed the timer. A running timer
+// is restored with its original end time. Only one action can be undone.
+func (s *Server) ActionUndo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if s.strict(w) {
+		return
+	}
+
+	if s.undo == nil {
+		http.Error(w, "Nothing to undo", http.StatusConflict)
+		return
+	}
+	s.restore(*s.undo)
+	s.undo = nil
+
+	fmt.Fprint(w, s.refreshStatus(true))
+}
+
+// snapshot is the position of the timer saved for ActionUndo.
+type snapshot struct {
+	mode               Mode
+	state              string
+	t, began           time.Time
+	d, flowBreak, once time.Duration
+	planned            time.Duration
+	paused             time.Duration
+	pausedAt           time.Time
+	count, extra       int
+	workSinceLongBreak int
+	warned, overtime   bool
+	finished           int // not restored
+}
+
+func (s *Server) snapshot() snapshot {
+	return snapshot{s.mode, s.state, s.t, s.began, s.d, s.flowBreak, s.once, s.planned, s.paused, s.pausedAt, s.count, s.extra, s.workSinceLongBreak, s.warned, s.overtime, s.finished}
+}
+
+func (s *Server) restore(snap snapshot) {
+	s.mode, s.state, s.t, s.began, s.d, s.flowBreak = snap.mode, snap.state, snap.t, snap.began, snap.d, snap.flowBreak
+	s.count, s.extra, s.workSinceLongBreak, s.warned = snap.count, snap.extra, snap.workSinceLongBreak, snap.warned
+	s.overtime, s.once, s.planned, s.paused, s.pausedAt = snap.overtime, snap.once, snap.planned, snap.paused, snap.pausedAt
+}
+
+// remember keeps before for ActionUndo if the action changed the timer. An
+// action that completed an interval can not be undone, since the interval is
+// in the stats and the history already.
+func (s *Server) remember(before snapshot) {
+	switch {
+	case s.finished != before.finished:
+		s.undo = nil
+	case s.snapshot() != before:
+		s.undo = &before
+	}
+}
+
+// stateChanged responds with 409 and returns true if the request sets if=
+// to a state label other than the current one. Actions run one at a time,
+// so a client that read the state from /status can make sure its action
+// applies to that state and not to the result of a concurrent action.
+func (s *Server) stateChanged(w http.ResponseWriter, r *http.Request) bool {
+	want := r.FormValue("if")
+	if want == "" || want == stateLabel(s.state) {
+		return false
+	}
+	http.Error(w, fmt.Sprintf("The timer is %v, not %v", stateLabel(s.state), want), http.StatusConflict)
+	return true
+}
+
+// resting responds with 409 and returns true if a work interval would start
+// before the -min-break since the last work interval is over.
+func (s *Server) resting(w http.ResponseWriter) bool {
+	if s.mode != ModeWork || s.state != StateStopped {
+		return false
+	}
+	left := s.restUntil.Sub(timeNow())
+	if left <= 0 {
+		return false
+	}
+	left = (left + time.Second - 1).Truncate(time.Second)
+	http.Error(w, fmt.Sprintf("Take %v more of your break before starting work", left), http.StatusConflict)
+	return true
+}
+
+// minBreak returns the part of a break of duration d that has to pass
+// before the next work interval can start.
+func minBreak(d time.Duration) time.Duration {
+	if MinBreakPercent > 0 {
+		return d * time.Duration(MinBreakPercent) / 100
+	}
+	return MinBreak
+}
+
+// strict responds with 409 and returns true if -strict forbids pausing or
+// ending the current interval early.
+func (s *Server) strict(w http.ResponseWriter) bool {
+	if !s.strictWork() {
+		return false
+	}
+	http.Error(w, "A running work interval can not be paused or stopped with -strict", http.StatusConflict)
+	return true
+}
+
+// strictWork reports whether -strict protects the current interval: a
+// running work interval, except a stopwatch. Work in overtime has already
+// ended.
+func (s *Server) strictWork() bool {
+	return Strict && s.mode == ModeWork && s.state == StateRunning && !s.stopwatch() && !s.inOvertime()
+}
+
+// n returns the number of work intervals in the current cycle.
+func (s *Server) n() int {
+	if N == 0 {
+		return 0
+	}
+	return N + s.extra
+}
+
+// longBreakDue reports whether the work intervals since the last long break
+// call for another one. With N=0, a long break is never due.
+func (s *Server) longBreakDue() bool {
+	return s.n() > 0 && s.workSinceLongBreak >= s.n()
+}
+
+type scheduleConfig struct {
+	N         *int              `json:"n"`
+	Durations scheduleDurations `json:"durations"`
+	Cycle     string            `json:"cycle,omitempty"` // read only, see -cycle
+}
+
+// runtimeConfig is the effective configuration shown by GET /config. Only
+// the schedule can be changed with PUT /config.
+type runtimeConfig struct {
+	scheduleConfig
+	Separators runtimeSeparators `json:"separators"`
+	Commands   map[string]string `json:"commands,omitempty"`
+	Targets    *runtimeTargets   `json:"targets,omitempty"`
+	Tick       int               `json:"tick"` // in ms
+	Profile    string            `json:"profile,omitempty"`
+}
+
+type runtimeSeparators struct {
+	Work  string `json:"work"`
+	Break string `json:"break"`
+}
+
+type runtimeTargets struct {
+	URL         string `json:"url,omitempty"`
+	UUID        string `json:"uuid,omitempty"`
+	Webhook     string `json:"webhook,omitempty"`
+	TextFile    string `json:"text_file,omitempty"`
+	StateFile   string `json:"state_file,omitempty"`
+	HistoryFile string `json:"history_file,omitempty"`
+}
+
+type scheduleDurations struct {
+	Work       string `json:"work"`
+	ShortBreak string `json:"short"`
+	LongBreak  string `json:"long"`
+}
+
+// ConfigSchedule replaces N and the interval durations in one step. Omitted
+// fields keep their current value. Changes apply from the next interval.
+func (s *Server) ConfigSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.updateSchedule(w, r)
+}
+
+// Config reports the configuration on GET, changes N and the durations on
+// PUT like ConfigSchedule, and changes any field of configPatch on PATCH.
+func (s *Server) Config(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		data, _ := json.Marshal(currentConfig(r))
+		w.Write(data)
+
+	case "PUT":
+		s.updateSchedule(w, r)
+
+	case "PATCH":
+		s.patchConfig(w, r)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) updateSchedule(w http.ResponseWriter, r *http.Request) {
+	var req scheduleConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setSchedule(w, req)
+}
+
+// configPatch is the body of PATCH /config. Omitted fields are kept, and an
+// empty command removes it.
+type configPatch struct {
+	scheduleConfig
+	Commands map[string]string `json:"commands"`
+	Tick     *int              `json:"tick"` // in ms
+}
+
+func (s *Server) patchConfig(w http.ResponseWriter, r *http.Request) {
+	var req configPatch
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	for name, command := range req.Commands {
+		if flag.Lookup(name) == nil || name != "command" && !strings.HasSuffix(name, "-command") {
+			http.Error(w, fmt.Sprintf("Unknown command %q", name), http.StatusBadRequest)
+			return
+		}
+		if err := checkCommand(command); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid %v: %v", name, err), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Tick != nil && (*req.Tick <= 10 || *req.Tick >= 1000) {
+		http.Error(w, "Invalid tick value (must between 10 and 1000)", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.N != nil || req.Durations != (scheduleDurations{}) {
+		if len(Cycle) > 0 {
+			http.Error(w, "The schedule is set by -cycle", http.StatusConflict)
+			return
+		}
+		if err := s.applySchedule(req.scheduleConfig); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Interval=%v ShortBreak=%v LongBreak=%v N=%v", DurationWork, DurationShortBreak, DurationLongBreak, N)
+	}
+	for name, command := range req.Commands {
+		s.setOption(name, command)
+		log.Printf("Command -%v: %q", name, command)
+	}
+	if req.Tick != nil {
+		s.setOption("tick", strconv.Itoa(*req.Tick))
+		Tick = time.Duration(*req.Tick) * time.Millisecond
+		s.setTick(Tick)
+		log.Printf("Send update every %v", Tick)
+	}
+
+	data, _ := json.Marshal(currentConfig(r))
+	w.Write(data)
+}
+
+// setSchedule applies req and responds with the new schedule.
+func (s *Server) setSchedule(w http.ResponseWriter, req scheduleConfig) {
+	if len(Cycle) > 0 {
+		http.Error(w, "The schedule is set by -cycle", http.StatusConflict)
+		return
+	}
+	if err := s.applySchedule(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Interval=%v ShortBreak=%v LongBreak=%v N=%v", DurationWork, DurationShortBreak, DurationLongBreak, N)
+
+	data, _ := json.Marshal(currentSchedule())
+	w.Write(data)
+}
+
+// currentConfig returns the configuration shown to r. The commands and
+// targets, which may hold secrets such as a webhook URL, are left out
+// unless r carries the -token.
+func currentConfig(r *http.Request) runtimeConfig {
+	config := runtimeConfig{
+		scheduleConfig: currentSchedule(),
+		Separators:     runtimeSeparators{Work: SepColon, Break: SepBreak},
+		Tick:           int(Tick / time.Millisecond),
+		Profile:        Profile,
+	}
+	if Token != "" && hasToken(r) {
+		config.Commands = commandFlags()
+		config.Targets = &runtimeTargets{
+			URL:         URL,
+			UUID:        UUID,
+			Webhook:     Webhook,
+			TextFile:    TextFile,
+			StateFile:   StateFile,
+			HistoryFile: HistoryFile,
+		}
+	}
+	return config
+}
+
+func currentSchedule() scheduleConfig {
+	return scheduleConfig{
+		N:     &N,
+		Cycle: formatCycle(),
+		Durations: scheduleDurations{
+			Work:       DurationWork.String(),
+			ShortBreak: DurationShortBreak.String(),
+			LongBreak:  DurationLongBreak.String(),
+		},
+	}
+}
+
+// applySchedule validates all fields of req before changing any of them.
+func (s *Server) applySchedule(req scheduleConfig) error {
+	n, work, short, long, err := req.resolve()
+	if err != nil {
+		return err
+	}
+	// Only the options given are kept by later profile switches.
+	if req.N != nil {
+		s.setOption("n", strconv.Itoa(n))
+	}
+	if req.Durations.Work != "" {
+		s.setOption("work", work.String())
+	}
+	if req.Durations.ShortBreak != "" {
+		s.setOption("short", short.String())
+	}
+	if req.Durations.LongBreak != "" {
+		s.setOption("long", long.String())
+	}
+	DurationWork, DurationShortBreak, DurationLongBreak = work, short, long
+	return nil
+}
+
+// resolve returns the schedule with the fields of req applied to the
+// current one.
+func (req scheduleConfig) resolve() (n int, work, short, long time.Duration, err error) {
+	n, work, short, long = N, DurationWork, DurationShortBreak, DurationLongBreak
+	if req.N != nil {
+		n = *req.N
+		if n < 0 {
+			err = fmt.Errorf("Invalid number of intervals (%v)", n)
+			return
+		}
+	}
+	if req.Durations.Work != "" {
+		if work, err = parseDuration(req.Durations.Work); err != nil {
+			return
+		}
+	}
+	if req.Durations.ShortBreak != "" {
+		if short, err = parseDuration(req.Durations.ShortBreak); err != nil {
+			return
+		}
+	}
+	if req.Durations.LongBreak != "" {
+		if long, err = parseDuration(req.Durations.LongBreak); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *Server) nextMode() {
+	finished := s.mode
+	defer s.postWebhook(finished)
+	s.flowBreak = 0
+	s.warned = false
+
+	if len(Cycle) > 0 {
+		s.nextStep()
+		if finished == ModeWork && s.mode != ModeWork {
+			s.restUntil = timeNow().Add(minBreak(s.duration()))
+		}
+		return
+	}
+
+	switch s.mode {
+	case ModeShortBreak, ModeLongBreak:
+		if s.mode == ModeLongBreak {
+			s.count = 0
+			s.workSinceLongBreak = 0
+			s.extra = 0
+		}
+
+		s.mode = ModeWork
+
+	case ModeWork:
+		s.count++
+		s.workSinceLongBreak++
+		if !s.longBreakDue() {
+			s.mode = ModeShortBreak
+		} else {
+			s.mode = ModeLongBreak
+		}
+		s.restUntil = timeNow().Add(minBreak(s.duration()))
+
+	default:
+		panic("unexpected")
+	}
+}
+
+// minFlowBreak is the shortest break with -flowtime, so that a work interval
+// stopped right away is neither followed by a break of a few seconds nor by
+// the full break.
+const minFlowBreak = time.Minute
+
+// setFlowBreak sets the length of the break that follows a work interval
+// with -flowtime.
+func (s *Server) setFlowBreak(d time.Duration) {
+	if d < minFlowBreak {
+		d = minFlowBreak
+	}
+	s.flowBreak = d
+	s.restUntil = timeNow().Add(minBreak(d))
+}
+
+// duration returns the length of the current interval: the one-off duration
+// of ActionStart, the flowtime break if one was set, the interval of -cycle, or the configured duration of the
+// mode.
+func (s *Server) duration() time.Duration {
+	if s.planned > 0 {
+		// Later changes of the options or the time of day do not change an
+		// interval that has started.
+		return s.planned
+	}
+	if s.once > 0 {
+		return s.once
+	}
+	if s.flowBreak > 0 && s.mode != ModeWork {
+		return s.flowBreak
+	}
+	if len(Cycle) > 0 {
+		return Cycle[cycleIndex(s.workSinceLongBreak, s.mode)].d
+	}
+	return s.mode.Duration()
+}
+
+// postWebhook sends the transition from finished to the current mode to
+// -webhook without blocking.
+func (s *Server) postWebhook(finished Mode) {
+	if Webhook == "" {
+		return
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"finished_mode": finished,
+		"mode":          s.mode,
+		"count":         s.count,
+		"time":          timeNow().Format(time.RFC3339),
+	})
+	webhook := Webhook
+	s.requests.Add(1)
+	go func() {
+		defer s.requests.Done()
+		resp, err := httpClient.Post(webhook, "application/json", bytes.NewReader(data))
 		if err == nil {
-			go func() {
-				err2 := cmd.Wait()
-				if err2 != nil {
-					printCommandError(err)
-				} else {
-					log.Println("Command executed")
-				}
-			}()
+			resp.Body.Close()
+			if resp.StatusCode/100 != 2 {
+				err = fmt.Errorf("Response status: %v", resp.Status)
+			}
+		}
+		if err != nil {
+			log.Printf("Error while sending webhook: %v", err)
+		}
+	}()
+}
+
+// endCommand returns the command to run when an interval of mode ends,
+// falling back to -command.
+func endCommand(mode Mode) string {
+	var command string
+	switch mode {
+	case ModeWork:
+		command = CommandWork
+	case ModeShortBreak:
+		command = CommandShortBreak
+	case ModeLongBreak:
+		command = CommandLongBreak
+	}
+	if command == "" {
+		command = Command
+	}
+	return command
+}
+
+// queuedCommand is a command waiting for runCommands.
+type queuedCommand struct {
+	command string
+	cmd     *exec.Cmd
+	async   bool
+}
+
+// runCommand executes command in a shell, respecting -async and -dry-run.
+// The environment is taken now, but the command runs later in runCommands,
+// so that s.mu is not held while it runs.
+func (s *Server) runCommand(command string) {
+	if command == "" {
+		return
+	}
+	if DryRun {
+		log.Printf("Dry run, not executing command: %q", command)
+		return
+	}
+
+	cmd := execCommand("/bin/sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = s.commandEnv()
+	s.metrics.commands++
+
+	s.running.Add(1)
+	s.queue = append(s.queue, queuedCommand{command, cmd, CommandAsync})
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+}
+
+// runCommands runs the commands of runCommand in order. Without -async,
+// each command finishes before the next one starts.
+func (s *Server) runCommands() {
+	for range s.queued {
+		s.mu.Lock()
+		queue := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, c := range queue {
+			s.execute(c)
 		}
-	} else {
-		err = cmd.Run()
-		if err == nil {
+	}
+}
+
+func (s *Server) execute(c queuedCommand) {
+	failed := func(err error) {
+		s.mu.Lock()
+		s.metrics.commandFailures++
+		s.mu.Unlock()
+		printCommandError(c.command, err)
+	}
+
+	if !c.async {
+		defer s.running.Done()
+		if err := c.cmd.Run(); err != nil {
+			failed(err)
+			return
+		}
+		log.Println("Command executed")
+		return
+	}
+
+	log.Println("Executing command (without waiting it to finish)...")
+	if err := c.cmd.Start(); err != nil {
+		s.running.Done()
+		failed(err)
+		return
+	}
+	go func() {
+		defer s.running.Done()
+		if err := c.cmd.Wait(); err != nil {
+			failed(err)
+		} else {
 			log.Println("Command executed")
 		}
+	}()
+}
+
+// remind runs the end command of the current mode, which ended but waits
+// for /action/ack, and shows the -notify notification.
+func (s *Server) remind() {
+	s.runCommand(quietCommand(endCommand(s.mode)))
+	if Notify && !quiet() {
+		go notify(s.mode, s.upcoming())
 	}
-	if err != nil {
-		printCommandError(err)
+}
+
+// ack switches to the next mode after an interval that waited for
+// /action/ack. With -auto, -auto-break or -auto-work, the next interval
+// may start right away.
+func (s *Server) ack() {
+	s.finish(s.t)
+	s.state = StateStopped
+	if s.autoStart() {
+		s.toggle()
+	}
+}
+
+// autoStart reports whether the current mode starts by itself when the
+// previous interval ends.
+func (s *Server) autoStart() bool {
+	if s.mode == ModeWork {
+		return AutoAdvance || AutoWork
+	}
+	return AutoAdvance || AutoBreak
+}
+
+// upcoming returns the mode that follows the current one.
+func (s *Server) upcoming() Mode {
+	if len(Cycle) > 0 {
+		return Cycle[(cycleIndex(s.workSinceLongBreak, s.mode)+1)%len(Cycle)].mode
+	}
+	switch {
+	case s.mode != ModeWork:
+		return ModeWork
+	case s.n() == 0, s.workSinceLongBreak+1 < s.n():
+		return ModeShortBreak
+	}
+	return ModeLongBreak
+}
+
+// intervalEnded runs the end command of the finished mode and shows the
+// -notify notification. The mode has already advanced.
+func (s *Server) intervalEnded(finished Mode) {
+	s.runCommand(quietCommand(endCommand(finished)))
+	if Notify && !quiet() {
+		go notify(finished, s.mode)
 	}
 }
 
-func printCommandError(err error) {
-	log.Println("Failed to execute command at end of timer:", err)
+// commandEnv returns the environment for commands, extended with the
+// current timer context.
+func (s *Server) commandEnv() []string {
+	return append(os.Environ(),
+		"TOMATO_MODE="+string(s.mode),
+		"TOMATO_STATE="+stateLabel(s.state),
+		"TOMATO_TIMER="+s.formatTimer(),
+		"TOMATO_COUNT="+strconv.Itoa(s.count),
+		"TOMATO_N="+strconv.Itoa(s.n()),
+	)
+}
+
+func printCommandError(command string, err error) {
+	log.Printf("Failed to execute command %q: %v", command, err)
 
 	if strings.Contains(err.Error(), "exit status 127") &&
-		strings.Contains(Command, "terminal-notifier") {
+		strings.Contains(command, "terminal-notifier") {
 		log.Println("Note: You may need to download terminal-notifier at https://github.com/julienXX/terminal-notifier")
+		log.Println("Note: -notify shows notifications without terminal-notifier")
 	}
 }
 
+// RefreshStatus checks whether the current interval has ended, advances the
+// mode when it has, and pushes the timer to the configured outputs.
 func (s *Server) RefreshStatus(output bool) string {
-	switch s.state {
-	case StateRunning:
-		if time.Now().After(s.t) {
-			s.state = StateStopped
-			s.nextMode()
-			s.executeCommand()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.refreshStatus(output)
+}
+
+func (s *Server) refreshStatus(output bool) string {
+	if s.checkUntil() {
+		output = true
+	}
+	if s.checkSchedule() {
+		output = true
+	}
+	s.checkReminders()
+	s.checkEyes()
+	switch {
+	case s.state == StateRunning && !s.stopwatch():
+		if WarnBefore > 0 {
+			// Fire once per crossing; adding time re-arms the warning.
+			if s.t.Sub(timeNow()) > WarnBefore {
+				s.warned = false
+			} else if !s.warned {
+				s.warned = true
+				s.runCommand(CommandOnWarn)
+			}
+		}
+		if now := timeNow(); now.After(s.t) && !s.overtime {
 			output = true
+			if Overtime && s.mode == ModeWork {
+				// Keep running and count up until stopped.
+				s.overtime = true
+				s.remind()
+				break
+			}
+			if RequireAck {
+				// Keep the mode until /action/ack.
+				s.state = StateFinished
+				s.reminded = now
+				s.remind()
+				break
+			}
+			finished := s.mode
+			s.finish(s.t)
+			s.state = StateStopped
+			if !s.autoStart() {
+				s.intervalEnded(finished)
+				if RichNotify && runtime.GOOS == "darwin" && !quiet() {
+					go s.richNotify(finished, s.mode, stateLabel(s.state))
+				}
+			} else {
+				// Start from now rather than from the old end time, so at
+				// most one interval advances per refresh (e.g. after sleep).
+				end := now
+				if CatchUp {
+					// Replay the intervals that ended while the timer was
+					// not refreshed, and land in the one running now. Only
+					// the last finished interval runs its command.
+					end = s.t
+					for !end.Add(s.duration()).After(now) {
+						end = end.Add(s.duration())
+						finished = s.mode
+						s.finish(end)
+						if !s.autoStart() {
+							break
+						}
+					}
+				}
+				s.intervalEnded(finished)
+				if s.autoStart() {
+					s.began = end
+					s.planned = s.duration()
+					s.t = end.Add(s.duration())
+					s.state = StateRunning
+					s.warned = false
+					s.runCommand(CommandOnStart)
+				}
+			}
+		}
+	case s.state == StateFinished:
+		if now := timeNow(); now.Sub(s.reminded) >= AckReminder {
+			s.reminded = now
+			s.remind()
+		}
+	}
+	s.saveState()
+
+	// Ticks between two changes of the displayed second render the same
+	// status, so only refresh the outputs when something visible changed.
+	shown := shownStatus{s.mode, s.state, s.overtime, s.shownDuration() / time.Second, s.watch.elapsed() / time.Second, s.untilLeft() / time.Second, s.count, s.n()}
+	if !output && shown == s.shown {
+		return s.shownTimer
+	}
+	s.shown = shown
+	s.shownTimer = s.outputStatus(output)
+	return s.shownTimer
+}
+
+// Nudge refreshes the outputs and resends the timer to BetterTouchTool even
+// if nothing changed.
+func (s *Server) Nudge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	forgetLastRequest()
+	s.shown = shownStatus{}
+	s.refreshStatus(false)
+}
+
+// finish records the end of the current interval at t and advances to the
+// next mode.
+func (s *Server) finish(t time.Time) {
+	s.endInterval(OutcomeCompleted, t)
+	s.finished++
+	s.undo = nil
+	if s.mode == ModeWork {
+		s.completed[dateKey(t)]++
+		s.statsDirty = true
+		s.metrics.workCompleted++
+		if Goal > 0 && s.completed[dateKey(t)] == Goal {
+			s.runCommand(CommandOnGoal)
 		}
+	} else {
+		s.metrics.breaksCompleted++
 	}
-	return s.outputStatus(output)
+	s.nextMode()
 }
 
 func (s *Server) formatStatusJSON() []byte {
-	data, _ := json.Marshal(map[string]interface{}{
-		"mode":  s.mode,
-		"state": s.state,
-		"timer": s.formatTimer(),
-		"i":     s.count,
-		"n":     N,
-	})
+	status := map[string]interface{}{
+		"mode":                  s.mode,
+		"state":                 stateLabel(s.state),
+		"timer":                 s.formatTimer(),
+		"i":                     s.count,
+		"work_since_long_break": s.workSinceLongBreak,
+		"n":                     N,
+		"effective_n":           s.n(),
+		"seq":                   s.seq,
+		"remaining_ms":          s.remaining() / time.Millisecond,
+		"remaining":             int(s.remaining() / time.Second),
+		"elapsed":               int(s.elapsed() / time.Second),
+	}
+	if Overtime {
+		status["overtime"] = 0
+		if s.inOvertime() {
+			status["overtime"] = int(s.shownDuration() / time.Second)
+		}
+	}
+	if s.watch.running() {
+		laps := []int{}
+		for _, d := range s.watch.lapTimes() {
+			laps = append(laps, int(d/time.Second))
+		}
+		status["stopwatch"] = map[string]interface{}{
+			"elapsed": int(s.watch.elapsed() / time.Second),
+			"laps":    laps,
+		}
+	}
+	if !s.until.IsZero() {
+		status["until"] = map[string]interface{}{
+			"t":         s.until.Format(time.RFC3339),
+			"remaining": int(s.untilLeft() / time.Second),
+		}
+	}
+	if Goal > 0 {
+		today := s.completed[dateKey(timeNow())]
+		status["goal"] = Goal
+		status["goalProgress"] = today
+		status["goal_reached"] = today >= Goal
+	}
+	data, _ := json.Marshal(status)
 	return data
 }
 
-func (s *Server) formatStatus() string {
-	return fmt.Sprintf("%v %v %d/%d %v", s.state, s.formatTimer(), s.count, N, s.mode)
+// stopwatch reports whether the current interval counts up and never ends
+// on its own.
+func (s *Server) stopwatch() bool {
+	return (Stopwatch || Flowtime) && s.mode == ModeWork
 }
 
-func (s *Server) formatTimer() string {
+// elapsed returns the time spent in the current interval.
+func (s *Server) elapsed() time.Duration {
+	if s.stopwatch() {
+		switch s.state {
+		case StateStopped:
+			return 0
+		case StatePaused:
+			return s.d
+		}
+		if d := timeNow().Sub(s.t); d > 0 {
+			return d
+		}
+		return 0
+	}
+	if d := s.duration() - s.remaining(); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// remaining returns the time left in the current interval, which is zero
+// for a stopwatch.
+func (s *Server) remaining() time.Duration {
+	if s.stopwatch() {
+		return 0
+	}
 	switch s.state {
 	case StateStopped:
-		return formatTimer(s.mode.Duration(), s.mode.Sep())
+		return s.duration()
+	case StatePaused:
+		return s.d
+	case StateRunning:
+		if d := s.t.Sub(timeNow()); d > 0 {
+			return d
+		}
+		return 0
+	case StateFinished:
+		return 0
+	}
+	panic("unexpected")
+}
+
+// stateLabel returns the label shown for state.
+func stateLabel(state string) string {
+	switch state {
+	case StateStopped:
+		return LabelStopped
 	case StatePaused:
-		return formatTimer(s.d, s.mode.Sep())
+		return LabelPaused
 	case StateRunning:
-		return formatTimer(s.t.Sub(time.Now()), s.mode.Sep())
+		return LabelRunning
+	case StateFinished:
+		return LabelFinished
 	}
 	panic("unexpected")
 }
 
+func (s *Server) formatStatus() string {
+	if s.n() == 0 {
+		// There is no long break to count towards.
+		return fmt.Sprintf("%v %v %d %v", stateLabel(s.state), s.formatTimer(), s.count, s.mode)
+	}
+	return fmt.Sprintf("%v %v %d/%d %v", stateLabel(s.state), s.formatTimer(), s.count, s.n(), s.mode)
+}
+
+func (s *Server) formatTimer() string {
+	if s.inOvertime() {
+		return "+" + formatTimer(s.shownDuration(), s.mode.Sep())
+	}
+	return formatTimer(s.shownDuration(), s.mode.Sep())
+}
+
+// inOvertime reports whether the running work interval has ended and counts
+// up, see -overtime.
+func (s *Server) inOvertime() bool {
+	return s.overtime && s.state == StateRunning
+}
+
+// shownDuration returns the duration shown by the timer: the elapsed time
+// for a stopwatch, the time past the end in overtime and the remaining time
+// otherwise.
+func (s *Server) shownDuration() time.Duration {
+	if s.stopwatch() {
+		return s.elapsed()
+	}
+	if s.inOvertime() {
+		return timeNow().Sub(s.t)
+	}
+	return s.remaining()
+}
+
 func (s *Server) outputStatus(output bool) string {
+	status := s.formatStatus()
+	if status != s.lastStatus {
+		s.lastStatus = status
+		s.seq++
+		s.publish()
+	}
 	if output {
-		log.Print(s.formatStatus())
+		log.Print(status)
 	}
 	str := s.formatTimer()
-	if URL != "" {
-		iconData := Icon1Data
-		if s.mode != ModeWork {
-			iconData = Icon2Data
+	if s.watch.running() {
+		str = formatTimer(s.watch.elapsed(), SepColon)
+	} else if !s.until.IsZero() {
+		str = formatTimer(s.untilLeft(), SepColon)
+	}
+	if TextFile != "" {
+		err := writeTextFile(str)
+		if err != nil {
+			log.Printf("Error while writing text file: %v", err)
+		}
+	}
+	if URL != "" && !quiet() {
+		iconData := s.icon()
+		text := s.widgetText(str)
+		// Most refreshes, e.g. every tick while paused, change nothing.
+		if isLastRequest(text, iconData) {
+			return str
 		}
+		// Number the update while holding s.mu, so doRequest can tell the
+		// newer update whichever goroutine gets there first.
+		seq := nextUpdate()
+		s.requests.Add(1)
 		go func() {
-			err := doRequest(str, iconData)
+			defer s.requests.Done()
+			err := doRequest(requestCtx, seq, text, iconData)
 			if err != nil {
+				s.mu.Lock()
+				s.metrics.requestFailures++
+				s.mu.Unlock()
 				log.Printf("Error while sending request: %v", err)
 			}
 		}()
@@ -451,6 +2567,166 @@ func (s *Server) outputStatus(output bool) string {
 	return str
 }
 
+// savedState is the part of Server persisted to StateFile. A running timer
+// stores its absolute end time so the remaining time survives a restart.
+type savedState struct {
+	Mode      Mode          `json:"mode"`
+	State     string        `json:"state"`
+	Count     int           `json:"count"`
+	Extra     int           `json:"extra,omitempty"`
+	Began     time.Time     `json:"began"`
+	SinceLong int           `json:"work_since_long_break"`
+	End       time.Time     `json:"end"`
+	Remaining time.Duration `json:"remaining,omitempty"`
+	Planned   time.Duration `json:"planned,omitempty"`
+}
+
+// stateFile is the content of StateFile.
+type stateFile struct {
+	savedState
+	Completed map[string]int `json:"completed,omitempty"`
+}
+
+func (s *Server) currentState() savedState {
+	st := savedState{Mode: s.mode, State: s.state, Count: s.count, Extra: s.extra, SinceLong: s.workSinceLongBreak}
+	switch s.state {
+	case StateRunning, StateFinished:
+		st.End = s.t
+		st.Began = s.began
+		st.Planned = s.planned
+	case StatePaused:
+		st.Remaining = s.d
+		st.Began = s.began
+		st.Planned = s.planned
+	}
+	return st
+}
+
+// saveState writes the current state to StateFile when it has changed since
+// the last write.
+func (s *Server) saveState() {
+	if StateFile == "" {
+		return
+	}
+	st := s.currentState()
+	if st == s.saved && !s.statsDirty {
+		return
+	}
+	data, _ := json.Marshal(stateFile{st, s.completed})
+	err := writeFileAtomic(StateFile, data)
+	if err != nil {
+		log.Printf("Error while saving state: %v", err)
+		return
+	}
+	s.saved = st
+	s.statsDirty = false
+}
+
+func (s *Server) loadState() {
+	data, err := ioutil.ReadFile(StateFile)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error while loading state: %v", err)
+		return
+	}
+	var f stateFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		log.Printf("Error while loading state: %v", err)
+		return
+	}
+	for day, n := range f.Completed {
+		s.completed[day] = n
+	}
+	st := f.savedState
+	if !st.Mode.Valid() {
+		log.Printf("Error while loading state: unknown mode %q", st.Mode)
+		return
+	}
+	if !inCycle(st.Mode) {
+		log.Printf("Not restoring the timer: %v", notInCycle(st.Mode))
+		return
+	}
+	switch st.State {
+	case StateRunning, StateFinished:
+		s.t = st.End
+		s.began = st.Began
+		s.planned = st.Planned
+	case StatePaused:
+		s.d = st.Remaining
+		s.began = st.Began
+		s.planned = st.Planned
+	case StateStopped:
+	default:
+		log.Printf("Error while loading state: unknown state %q", st.State)
+		return
+	}
+	// State files written before work_since_long_break existed only have
+	// the count, which drove the long break back then.
+	if st.SinceLong == 0 && st.Count > 0 {
+		st.SinceLong = st.Count
+	}
+	s.mode, s.state, s.count, s.extra = st.Mode, st.State, st.Count, st.Extra
+	s.workSinceLongBreak = st.SinceLong
+	s.saved = st
+	log.Printf("Restored state: %v", s.formatStatus())
+}
+
+func dateKey(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+var formatPlaceholder = regexp.MustCompile(`{[^{}]*}`)
+
+func validateFormat(format string) error {
+	for _, p := range formatPlaceholder.FindAllString(format, -1) {
+		switch p {
+		case "{timer}", "{mode}", "{state}", "{count}", "{n}":
+		default:
+			return fmt.Errorf("Unknown placeholder %v in -format", p)
+		}
+	}
+	return nil
+}
+
+// widgetText renders the text sent to BetterTouchTool for timer.
+func (s *Server) widgetText(timer string) string {
+	text := timer
+	if Format != "" {
+		text = strings.NewReplacer(
+			"{timer}", timer,
+			"{mode}", string(s.mode),
+			"{state}", stateLabel(s.state),
+			"{count}", strconv.Itoa(s.count),
+			"{n}", strconv.Itoa(s.n()),
+		).Replace(Format)
+	}
+	return TextPrefix + text + TextSuffix
+}
+
+// icon returns the base64 encoded icon for the current status.
+func (s *Server) icon() string {
+	if s.inOvertime() {
+		return IconOvertimeData
+	}
+	if !s.watch.running() && !s.until.IsZero() && IconAlarmData != "" {
+		return IconAlarmData
+	}
+	return modeIcon(s.mode)
+}
+
+// modeIcon returns the base64 encoded icon for mode.
+func modeIcon(mode Mode) string {
+	switch mode {
+	case ModeShortBreak:
+		return Icon2Data
+	case ModeLongBreak:
+		return Icon3Data
+	}
+	return Icon1Data
+}
+
 func fatalf(format string, args ...interface{}) {
 	fmt.Printf(format, args...)
 	fmt.Println()
@@ -458,40 +2734,75 @@ func fatalf(format string, args ...interface{}) {
 	os.Exit(1)
 }
 
+// formatTimer renders d as MM:SS, or as H:MM:SS from 100 minutes on. Times
+// beyond 99 hours are shown as 99:59:59.
 func formatTimer(d time.Duration, sep string) string {
 	if d < 0 {
 		d = 0
 	}
+	if max := 100*time.Hour - time.Second; d > max {
+		d = max
+	}
 	m := int(d / time.Minute)
 	s := int((d % time.Minute) / time.Second)
 	if m > 99 {
-		m = 99
+		return fmt.Sprintf("%d%s%02d%s%02d", m/60, sep, m%60, sep, s)
 	}
 
 	return fmt.Sprintf("%02d%s%02d", m, sep, s)
 }
 
-func parseDuration(s string) time.Duration {
-	if s == "" {
-		fatalf("Invalid duration `%v`", s)
+func parseDuration(s string) (time.Duration, error) {
+	// A bare number without unit is treated as minutes.
+	str := s
+	if _, err := strconv.Atoi(s); err == nil {
+		str += "m"
 	}
-	unit := time.Minute
-	switch s[len(s)-1] {
-	case 'm':
-		s = s[:len(s)-1]
-	case 's':
-		unit = time.Second
-		s = s[:len(s)-1]
-	}
-	i, err := strconv.Atoi(s)
-	if err != nil {
-		fatalf("Invalid duration `%v`", s)
+	d, err := time.ParseDuration(str)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("Invalid duration `%v`", s)
 	}
+	return d, nil
+}
+
+// setFlagsFromEnv sets each flag not in set, the flags given on the command
+// line, from its TOMATO_* environment variable, e.g. TOMATO_SHORT_COMMAND for
+// -short-command. The values go through the same validation as flags.
+func setFlagsFromEnv(set map[string]bool) error {
+	var err error
+	fromEnv := map[string]bool{}
+	flag.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || fromEnv[f.Name] || err != nil {
+			return
+		}
+		name := envName(f.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if e := flag.Set(f.Name, value); e != nil {
+			err = fmt.Errorf("Invalid value %q for %v: %v", value, name, e)
+		}
+		// The first of a flag and its alias wins.
+		for alias, name := range flagAliases {
+			if f.Name == alias || f.Name == name {
+				fromEnv[alias], fromEnv[name] = true, true
+			}
+		}
+	})
+	return err
+}
 
-	if i <= 0 {
-		fatalf("Invalid duration `%v`", s)
+func envName(flagName string) string {
+	return "TOMATO_" + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+func mustParseDuration(s string) time.Duration {
+	d, err := parseDuration(s)
+	if err != nil {
+		fatalf("%v", err)
 	}
-	return time.Duration(i) * unit
+	return d
 }
 
 func mustLoad(data []byte, err error) []byte {
@@ -501,26 +2812,118 @@ func mustLoad(data []byte, err error) []byte {
 	return data
 }
 
-func mustLoadIcon(filename, defaultIcon string) string {
+// iconData holds the base64 encoded icons.
+type iconData struct {
+	icon1, icon2, icon3, overtime, alarm string
+}
+
+// loadIcons reads the icons named by -icon1, -icon2, -icon3, -icon-overtime
+// and -icon-alarm in o.
+func loadIcons(o options) (*iconData, error) {
+	icon1, err := loadIcon(o["icon1"], "red.png")
+	if err != nil {
+		return nil, err
+	}
+	icon2, err := loadIcon(o["icon2"], "green.png")
+	if err != nil {
+		return nil, err
+	}
+	icons := &iconData{icon1: icon1, icon2: icon2, icon3: icon2}
+	if o["icon3"] != "" {
+		if icons.icon3, err = loadIcon(o["icon3"], "green.png"); err != nil {
+			return nil, err
+		}
+	}
+	if icons.overtime, err = loadIcon(o["icon-overtime"], "orange.png"); err != nil {
+		return nil, err
+	}
+	if o["icon-alarm"] != "" {
+		if icons.alarm, err = loadIcon(o["icon-alarm"], "red.png"); err != nil {
+			return nil, err
+		}
+	}
+	return icons, nil
+}
+
+func (icons *iconData) apply() {
+	Icon1Data, Icon2Data, Icon3Data = icons.icon1, icons.icon2, icons.icon3
+	IconOvertimeData, IconAlarmData = icons.overtime, icons.alarm
+}
+
+// loadIcon returns the base64 encoded icon in filename, or the built-in
+// defaultIcon if filename is empty.
+func loadIcon(filename, defaultIcon string) (string, error) {
 	var data []byte
+	var err error
 	if filename == "" {
-		data = mustLoad(Asset(defaultIcon))
+		data, err = Asset(defaultIcon)
 	} else {
-		data = mustLoad(ioutil.ReadFile(filename))
+		data, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return "", err
 	}
-	return base64.StdEncoding.EncodeToString(data)
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+var (
+	lastMu             sync.Mutex
+	lastText, lastIcon string             // last values sent to BetterTouchTool
+	wantText, wantIcon string             // latest values requested by doRequest
+	updates            int64              // number of the last update, see nextUpdate
+	wantSeq            int64              // number of the update for wantText and wantIcon
+	cancelWant         context.CancelFunc // cancels the request for wantText and wantIcon
+)
+
+// requestCtx is the parent context of all requests to BetterTouchTool.
+// cancelRequests aborts the requests in flight on shutdown.
+var requestCtx, cancelRequests = context.WithCancel(context.Background())
+
+// forgetLastRequest makes the next doRequest send even if the text and icon
+// are unchanged, e.g. to restore the widget after BetterTouchTool restarts.
+func forgetLastRequest() {
+	lastMu.Lock()
+	lastText, lastIcon = "", ""
+	lastMu.Unlock()
+}
+
+// nextUpdate numbers a new update for doRequest.
+func nextUpdate() int64 {
+	lastMu.Lock()
+	defer lastMu.Unlock()
+	updates++
+	return updates
 }
 
-var lastText, lastIcon string
+// isLastRequest reports whether text and iconData are what was last sent.
+func isLastRequest(text, iconData string) bool {
+	lastMu.Lock()
+	defer lastMu.Unlock()
+	return text == lastText && iconData == lastIcon
+}
 
-func doRequest(text string, iconData string) error {
-	if text == lastText && iconData == lastIcon {
+// doRequest sends text and iconData to BetterTouchTool, retrying up to
+// HTTPRetries times. seq numbers the update in the order it was made: an
+// update older than one already requested is dropped, and a newer update
+// cancels it, so a slow request never overwrites the newer values.
+func doRequest(ctx context.Context, seq int64, text string, iconData string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lastMu.Lock()
+	if seq < wantSeq {
+		lastMu.Unlock()
+		return nil
+	}
+	if cancelWant != nil {
+		cancelWant()
+	}
+	wantText, wantIcon, wantSeq, cancelWant = text, iconData, seq, cancel
+	unchanged := text == lastText && iconData == lastIcon
+	lastMu.Unlock()
+	if unchanged {
 		return nil
 	}
-	defer func() {
-		lastText = text
-		lastIcon = iconData
-	}()
 
 	u, err := url.Parse(URL)
 	if err != nil {
@@ -532,12 +2935,76 @@ func doRequest(text string, iconData string) error {
 	q.Set("icon_data", iconData)
 	u.RawQuery = q.Encode()
 
-	resp, err := httpClient.Get(u.String())
+	for i := 0; ; i++ {
+		err = sendRequest(ctx, u.String())
+		if err == nil || i >= HTTPRetries || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-time.After(time.Duration(i+1) * 50 * time.Millisecond):
+		case <-ctx.Done():
+		}
+	}
+
+	lastMu.Lock()
+	defer lastMu.Unlock()
+	if ctx.Err() != nil {
+		// Superseded by a newer update, or shutting down.
+		return nil
+	}
+	if err != nil {
+		// Not sent, so the next update sends it even if it is unchanged.
+		return err
+	}
+	lastText = text
+	lastIcon = iconData
+	return nil
+}
+
+func sendRequest(ctx context.Context, u string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
+	resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("Response status: %v", resp.Status)
 	}
 	return nil
 }
+
+var lastFileText string
+
+func writeTextFile(text string) error {
+	if text == lastFileText {
+		return nil
+	}
+	err := writeFileAtomic(TextFile, []byte(text))
+	if err != nil {
+		return err
+	}
+	lastFileText = text
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory, then
+// renames it over filename, so readers never observe a partially written file.
+func writeFileAtomic(filename string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
@@ -0,0 +1,2803 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(ioutil.Discard)
+	os.Exit(m.Run())
+}
+
+// fakeClock replaces timeNow in tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// commandLog replaces execCommand in tests and records the commands instead
+// of running them.
+type commandLog struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+func (l *commandLog) Command(name string, args ...string) *exec.Cmd {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.commands = append(l.commands, args[len(args)-1])
+	return exec.Command("/bin/sh", "-c", ":")
+}
+
+// Take returns the commands recorded since the last call.
+func (l *commandLog) Take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	commands := l.commands
+	l.commands = nil
+	return commands
+}
+
+// setup resets every option to its default and returns a new server on a
+// fake clock, at 09:00 on a Monday, whose commands are recorded.
+func setup(t *testing.T) (*Server, *fakeClock, *commandLog) {
+	t.Helper()
+	flag.VisitAll(func(f *flag.Flag) {
+		if !strings.HasPrefix(f.Name, "test.") {
+			f.Value.Set(f.DefValue)
+		}
+	})
+	URL, Profiles, fixedFlags = "", nil, nil
+	Cycle, WeekdayDurations, HourDurations, Quiet = nil, nil, nil, nil
+	httpClient.Timeout = 200 * time.Millisecond
+	st, err := currentOptions().parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.apply()
+
+	clock := &fakeClock{now: time.Date(2024, time.January, 1, 9, 0, 0, 0, time.Local)}
+	commands := &commandLog{}
+	timeNow, execCommand = clock.Now, commands.Command
+	t.Cleanup(func() { timeNow, execCommand = time.Now, exec.Command })
+	return NewServer(), clock, commands
+}
+
+// do sends a request to h and returns the response.
+func do(h http.Handler, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, body))
+	return rec
+}
+
+// writeFile writes data to name in a temporary directory and returns its
+// path.
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(filename, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestStrictOvertime(t *testing.T) {
+	s, clock, _ := setup(t)
+	Strict, Overtime = true, true
+	defer func() { Strict, Overtime = false, false }()
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	if rec := do(h, "POST", "/action/stop", nil); rec.Code != http.StatusConflict {
+		t.Errorf("stop while running: %v, want 409", rec.Code)
+	}
+	clock.Add(26 * time.Minute)
+	s.RefreshStatus(false)
+	if !s.inOvertime() {
+		t.Fatalf("not in overtime")
+	}
+	if rec := do(h, "POST", "/action/stop", nil); rec.Code != http.StatusOK {
+		t.Errorf("stop in overtime: %v %v", rec.Code, rec.Body)
+	}
+	if s.mode != ModeShortBreak || s.state != StateStopped || s.count != 1 {
+		t.Errorf("mode=%v state=%v count=%v", s.mode, s.state, s.count)
+	}
+}
+
+// TestStrictActions checks that every action that would end or replace a
+// running work interval answers 409 with -strict.
+func TestStrictActions(t *testing.T) {
+	s, _, _ := setup(t)
+	Strict = true
+	h := s.Handler()
+	do(h, "POST", "/action/start", nil)
+	began := s.began
+	for _, target := range []string{
+		"/action/pause", "/action/toggle", "/action/stop", "/action/skip", "/action/reset",
+		"/action/restart", "/action/ack", "/action/cycle?i=2", "/action/undo",
+		"/action/mode?mode=short-break", "/action/set?remaining=1m", "/action/add?d=-5m",
+	} {
+		if rec := do(h, "POST", target, nil); rec.Code != http.StatusConflict {
+			t.Errorf("POST %v: %v %v, want 409", target, rec.Code, rec.Body)
+		}
+		if s.mode != ModeWork || s.state != StateRunning || !s.began.Equal(began) || s.workSinceLongBreak != 0 {
+			t.Fatalf("after POST %v: %v %v began=%v", target, s.mode, stateLabel(s.state), s.began)
+		}
+	}
+	if rec := do(h, "POST", "/action/add?d=5m", nil); rec.Code != http.StatusOK {
+		t.Errorf("adding time: %v %v", rec.Code, rec.Body)
+	}
+}
+
+// TestStrictScheduledStart checks that a scheduled start waits for a work
+// interval under -strict, and that -start-at leaves it alone.
+func TestStrictScheduledStart(t *testing.T) {
+	s, clock, _ := setup(t)
+	Strict = true
+	h := s.Handler()
+	do(h, "POST", "/action/start", nil)
+
+	s.ScheduledStart() // as by -start-at
+	if s.mode != ModeWork || s.state != StateRunning || !s.began.Equal(clock.Now()) {
+		t.Errorf("after -start-at: %v %v began=%v", s.mode, stateLabel(s.state), s.began)
+	}
+
+	if rec := do(h, "POST", "/schedule?at=09:10", nil); rec.Code != http.StatusOK {
+		t.Fatalf("POST /schedule: %v %v", rec.Code, rec.Body)
+	}
+	clock.Add(11 * time.Minute)
+	s.RefreshStatus(false)
+	if s.state != StateRunning || s.began.Hour() != 9 || s.began.Minute() != 0 || len(s.scheduled) != 1 {
+		t.Errorf("at 09:11: %v began=%v scheduled=%v, want the work interval kept", stateLabel(s.state), s.began, s.scheduled)
+	}
+
+	// The scheduled start follows the end of the work interval.
+	clock.Add(15 * time.Minute)
+	s.RefreshStatus(false)
+	s.RefreshStatus(false)
+	records, _ := s.storage.ListSessions(sessionFilter{})
+	if len(records) != 1 || records[0].Outcome != OutcomeCompleted {
+		t.Errorf("history: %+v, want the work interval completed", records)
+	}
+	if s.mode != ModeWork || s.state != StateRunning || !s.began.Equal(clock.Now()) || len(s.scheduled) != 0 {
+		t.Errorf("at 09:26: %v %v began=%v scheduled=%v, want the scheduled work interval", s.mode, stateLabel(s.state), s.began, s.scheduled)
+	}
+}
+
+// TestSchedule queues work intervals with /schedule and checks that they
+// start at their time, with their preset.
+func TestSchedule(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+	for _, target := range []string{"/schedule", "/schedule?at=25:00", "/schedule?at=10:00&preset=nap"} {
+		if rec := do(h, "POST", target, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %v: %v, want 400", target, rec.Code)
+		}
+	}
+	do(h, "POST", "/schedule?at=15:00", nil)
+	do(h, "POST", "/schedule?at=08:00", nil) // tomorrow
+	do(h, "POST", "/schedule?at=10:00&preset=ultradian", nil)
+	rec := do(h, "GET", "/schedule", nil)
+	var pending []scheduledStart
+	if err := json.Unmarshal(rec.Body.Bytes(), &pending); err != nil || len(pending) != 3 ||
+		pending[0].At.Hour() != 10 || pending[0].Preset != "ultradian" || pending[1].At.Hour() != 15 || pending[2].At.Day() != 2 {
+		t.Fatalf("GET /schedule = %v, want 10:00, 15:00 and 08:00 tomorrow", rec.Body)
+	}
+	if rec := do(h, "DELETE", "/schedule?at=15:00", nil); strings.Contains(rec.Body.String(), "T15:00") || len(s.scheduled) != 2 {
+		t.Errorf("DELETE /schedule?at=15:00 = %v", rec.Body)
+	}
+
+	// A running break is stopped for the scheduled work interval.
+	do(h, "POST", "/action/skip", nil)
+	clock.Add(58 * time.Minute)
+	do(h, "POST", "/action/start", nil)
+	clock.Add(2*time.Minute - time.Second)
+	s.RefreshStatus(false)
+	if s.mode != ModeShortBreak {
+		t.Fatalf("mode=%v before 10:00", s.mode)
+	}
+	clock.Add(time.Second)
+	s.RefreshStatus(false)
+	if s.mode != ModeWork || s.state != StateRunning || s.remaining() != 90*time.Minute || len(s.scheduled) != 1 {
+		t.Errorf("at 10:00: %v %v remaining=%v pending=%v, want 90m of work", s.mode, s.state, s.remaining(), len(s.scheduled))
+	}
+	records, _ := s.storage.ListSessions(sessionFilter{})
+	if len(records) != 1 || records[0].Mode != ModeShortBreak || records[0].Outcome != OutcomeStopped {
+		t.Errorf("records=%+v, want the break stopped", records)
+	}
+
+	if rec := do(h, "DELETE", "/schedule", nil); rec.Body.String() != "[]" {
+		t.Errorf("DELETE /schedule = %v, want []", rec.Body)
+	}
+}
+
+// TestStartAt checks the times of -start-at and what a scheduled start does
+// to each state of the timer.
+func TestStartAt(t *testing.T) {
+	days, err := parseWeekdayList("mon,tue,wed,thu,fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(d, h, m int) time.Time {
+		return time.Date(2024, time.January, d, h, m, 0, 0, time.Local)
+	}
+	for _, test := range []struct{ now, want time.Time }{
+		{at(1, 8, 0), at(1, 9, 0)},  // Monday morning
+		{at(1, 9, 0), at(2, 9, 0)},  // Monday at 09:00
+		{at(5, 18, 0), at(8, 9, 0)}, // Friday evening
+		{at(6, 8, 0), at(8, 9, 0)},  // Saturday morning
+	} {
+		if got, err := nextStart("09:00", days, test.now); err != nil || !got.Equal(test.want) {
+			t.Errorf("nextStart at %v = %v, %v, want %v", test.now, got, err, test.want)
+		}
+	}
+
+	s, clock, _ := setup(t)
+	h := s.Handler()
+	do(h, "POST", "/action/skip", nil)
+	s.ScheduledStart()
+	if s.mode != ModeWork || s.state != StateRunning {
+		t.Errorf("from a stopped break: %v %v, want running work", s.mode, s.state)
+	}
+	do(h, "POST", "/action/pause", nil)
+	s.ScheduledStart()
+	if s.state != StatePaused {
+		t.Errorf("from a paused interval: %v, want it left alone", s.state)
+	}
+
+	// The goroutine of -start-at starts work at the time of day.
+	s, clock, _ = setup(t)
+	clock.Add(-50 * time.Millisecond)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.startDaily("09:00", days, done)
+	}()
+	defer wg.Wait()
+	defer close(done)
+	for i := 0; i < 100; i++ {
+		time.Sleep(20 * time.Millisecond)
+		s.mu.Lock()
+		state := s.state
+		s.mu.Unlock()
+		if state == StateRunning {
+			return
+		}
+	}
+	t.Errorf("-start-at=09:00 did not start work")
+}
+
+func TestOvertimeIcon(t *testing.T) {
+	setup(t)
+	icons, err := loadIcons(currentOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if icons.overtime == icons.icon1 || icons.overtime == icons.icon2 {
+		t.Errorf("the overtime icon is the same as another one")
+	}
+}
+
+// TestLongBreakCadence checks that the long break comes after every N work
+// intervals, however they end, and that changing the count does not move it.
+func TestLongBreakCadence(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+
+	// expire runs the current interval until it ends by itself.
+	expire := func() {
+		do(h, "POST", "/action/start", nil)
+		clock.Add(s.duration() + time.Second)
+		s.RefreshStatus(false)
+	}
+	skip := func() {
+		do(h, "POST", "/action/start", nil)
+		do(h, "POST", "/action/skip", nil)
+	}
+	for round := 0; round < 2; round++ {
+		for i := 1; i <= N; i++ {
+			if s.mode != ModeWork {
+				t.Fatalf("round %v, work %v: mode=%v", round, i, s.mode)
+			}
+			if i%2 == 0 {
+				skip()
+			} else {
+				expire()
+			}
+			if i == 2 {
+				do(h, "POST", "/action/set?count=0", nil)
+			}
+			want := ModeShortBreak
+			if i == N {
+				want = ModeLongBreak
+			}
+			if s.mode != want || s.workSinceLongBreak != i {
+				t.Fatalf("round %v, work %v: mode=%v work_since_long_break=%v", round, i, s.mode, s.workSinceLongBreak)
+			}
+			if i < N {
+				skip()
+			}
+		}
+		if s.count != N-2 {
+			t.Errorf("round %v: count=%v, want %v after /action/set?count=0", round, s.count, N-2)
+		}
+		expire()
+		if s.mode != ModeWork || s.count != 0 || s.workSinceLongBreak != 0 {
+			t.Fatalf("round %v after the long break: mode=%v count=%v work_since_long_break=%v", round, s.mode, s.count, s.workSinceLongBreak)
+		}
+	}
+
+	// /action/cycle moves the long break instead.
+	do(h, "POST", "/action/cycle?i=3", nil)
+	expire()
+	if s.mode != ModeLongBreak || s.count != 1 {
+		t.Errorf("after /action/cycle?i=3: mode=%v count=%v", s.mode, s.count)
+	}
+}
+
+// TestLongBreakN checks -n of 10 and more, and -n=0 for no long breaks.
+func TestLongBreakN(t *testing.T) {
+	for _, n := range []int{12, 0} {
+		s, clock, _ := setup(t)
+		N = n
+		h := s.Handler()
+		for i := 1; i <= 30; i++ {
+			do(h, "POST", "/action/start", nil)
+			clock.Add(s.duration() + time.Second)
+			s.RefreshStatus(false)
+			want := ModeShortBreak
+			if n > 0 && i%n == 0 {
+				want = ModeLongBreak
+			}
+			if s.mode != want {
+				t.Fatalf("n=%v, work %v: mode=%v, want %v", n, i, s.mode, want)
+			}
+			do(h, "POST", "/action/skip", nil)
+		}
+		if n == 0 && (s.count != 30 || s.workSinceLongBreak != 30) {
+			t.Errorf("n=0: count=%v work since long break=%v, want 30", s.count, s.workSinceLongBreak)
+		}
+		want := http.StatusOK
+		if n == 12 {
+			want = http.StatusBadRequest
+		}
+		if rec := do(h, "POST", "/action/cycle?i=13", nil); rec.Code != want {
+			t.Errorf("n=%v: POST /action/cycle?i=13: %v %v, want %v", n, rec.Code, rec.Body, want)
+		}
+	}
+
+	s, _, _ := setup(t)
+	h := s.Handler()
+	for _, test := range []struct {
+		body string
+		code int
+	}{
+		{`{"n": 0}`, http.StatusOK},
+		{`{"n": 15}`, http.StatusOK},
+		{`{"n": -1}`, http.StatusBadRequest},
+	} {
+		if rec := do(h, "PUT", "/config", strings.NewReader(test.body)); rec.Code != test.code {
+			t.Errorf("PUT /config %v: %v %v, want %v", test.body, rec.Code, rec.Body, test.code)
+		}
+	}
+	if N != 15 {
+		t.Errorf("N=%v, want 15", N)
+	}
+}
+
+func TestUndoAfterCompletion(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(time.Minute)
+	do(h, "POST", "/action/pause", nil)
+	if rec := do(h, "POST", "/action/undo", nil); rec.Code != http.StatusOK || s.state != StateRunning {
+		t.Fatalf("undo of pause: %v state=%v", rec.Code, s.state)
+	}
+
+	// The timer completes the interval by itself.
+	clock.Add(25 * time.Minute)
+	s.RefreshStatus(false)
+	if s.mode != ModeShortBreak || s.count != 1 {
+		t.Fatalf("mode=%v count=%v", s.mode, s.count)
+	}
+	if rec := do(h, "POST", "/action/undo", nil); rec.Code != http.StatusConflict {
+		t.Errorf("undo after expiry: %v, want 409", rec.Code)
+	}
+	if s.mode != ModeShortBreak || s.count != 1 {
+		t.Errorf("after undo: mode=%v count=%v", s.mode, s.count)
+	}
+
+	// Skipping an interval in overtime completes it too.
+	Overtime = true
+	defer func() { Overtime = false }()
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/start", nil)
+	clock.Add(26 * time.Minute)
+	s.RefreshStatus(false)
+	do(h, "POST", "/action/skip", nil)
+	if rec := do(h, "POST", "/action/undo", nil); rec.Code != http.StatusConflict {
+		t.Errorf("undo after skipping overtime: %v, want 409", rec.Code)
+	}
+	if s.count != 2 || s.completed[dateKey(timeNow())] != 2 {
+		t.Errorf("count=%v completed=%v", s.count, s.completed[dateKey(timeNow())])
+	}
+}
+
+func TestCloseSavesState(t *testing.T) {
+	s, clock, _ := setup(t)
+	StateFile = filepath.Join(t.TempDir(), "state.json")
+	defer func() { StateFile = "" }()
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(10 * time.Minute)
+	s.Close()
+	select {
+	case <-s.closed:
+	default:
+		t.Errorf("event streams are still open")
+	}
+
+	restored := NewServer()
+	if restored.state != StateRunning || !restored.t.Equal(s.t) {
+		t.Errorf("restored state=%v end=%v, want running until %v", restored.state, restored.t, s.t)
+	}
+	s.Close() // again, as on a second signal
+}
+
+// TestConcurrentActions runs the ticker and actions at the same time. Run it
+// with -race.
+func TestConcurrentActions(t *testing.T) {
+	s, clock, _ := setup(t)
+	Command, CommandOnStart = "end", "start"
+	h := s.Handler()
+
+	var wg sync.WaitGroup
+	for _, path := range []string{"/action/start", "/action/stop", "/action/pause", "/action/resume", "/action/skip", "/action/toggle"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				do(h, "POST", path, nil)
+				do(h, "GET", "/status", nil)
+			}
+		}(path)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			clock.Add(time.Minute)
+			s.RefreshStatus(false)
+		}
+	}()
+	wg.Wait()
+	s.running.Wait()
+}
+
+// TestCommandOutsideLock checks that a slow command does not hold up the
+// server.
+func TestCommandOutsideLock(t *testing.T) {
+	s, clock, _ := setup(t)
+	Command = "end"
+	release := make(chan struct{})
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		cmd := exec.Command("/bin/sh", "-c", "read line")
+		cmd.Stdin = &blockingReader{release}
+		return cmd
+	}
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(26 * time.Minute)
+	s.RefreshStatus(false)
+
+	done := make(chan int)
+	go func() { done <- do(h, "GET", "/healthz", nil).Code }()
+	select {
+	case code := <-done:
+		if code != http.StatusOK {
+			t.Errorf("GET /healthz: %v", code)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("GET /healthz waits for the end command")
+	}
+	close(release)
+	s.running.Wait()
+}
+
+// blockingReader blocks reads until release is closed, then reports the end
+// of input.
+type blockingReader struct {
+	release chan struct{}
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	<-r.release
+	return 0, io.EOF
+}
+
+func TestActionExtend(t *testing.T) {
+	s, _, _ := setup(t)
+	h := s.Handler()
+
+	for _, target := range []string{"/action/extend?dur=5m", "/action/extend?d=", "/action/extend?d=abc"} {
+		if rec := do(h, "POST", target, nil); rec.Code != http.StatusBadRequest || s.extra != 0 {
+			t.Errorf("POST %v: %v extra=%v, want 400 and no change", target, rec.Code, s.extra)
+		}
+	}
+	do(h, "POST", "/action/start", nil)
+	for _, test := range []struct{ target, want string }{
+		{"/action/extend?d=5m", "30:00"},
+		{"/action/extend?d=-10m", "20:00"},
+	} {
+		if rec := do(h, "POST", test.target, nil); rec.Code != http.StatusOK || rec.Body.String() != test.want || s.extra != 0 {
+			t.Errorf("POST %v: %v %v extra=%v, want %v", test.target, rec.Code, rec.Body, s.extra, test.want)
+		}
+	}
+	rec := do(h, "POST", "/action/extend", nil)
+	if want := `{"effective_n":5,"n":4}`; rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Errorf("POST /action/extend: %v %v, want %v", rec.Code, rec.Body, want)
+	}
+}
+
+// TestRequestOrder checks that an update made before another never replaces
+// it in BetterTouchTool, whichever reaches doRequest first.
+func TestRequestOrder(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		texts = append(texts, r.URL.Query().Get("text"))
+		mu.Unlock()
+	}))
+	defer btt.Close()
+
+	setup(t)
+	URL, UUID = btt.URL, "uuid"
+	defer func() { URL, UUID = "", "" }()
+	forgetLastRequest()
+
+	older, newer := nextUpdate(), nextUpdate()
+	if err := doRequest(requestCtx, newer, "new", "icon"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doRequest(requestCtx, older, "old", "icon"); err != nil {
+		t.Fatal(err)
+	}
+	if len(texts) != 1 || texts[0] != "new" || !isLastRequest("new", "icon") {
+		t.Errorf("sent %q, want only the newer update", texts)
+	}
+}
+
+// TestWarnEachInterval checks that -warn-command runs again in an interval
+// that starts within -warn of its end.
+func TestWarnEachInterval(t *testing.T) {
+	s, clock, commands := setup(t)
+	WarnBefore, CommandOnWarn = 5*time.Minute, "warn"
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(21 * time.Minute)
+	s.RefreshStatus(false)
+	clock.Add(5 * time.Minute)
+	s.RefreshStatus(false)
+	s.running.Wait()
+	if got := commands.Take(); !equalStrings(got, "warn") {
+		t.Fatalf("work: commands=%q", got)
+	}
+
+	// The short break is no longer than -warn.
+	do(h, "POST", "/action/start", nil)
+	s.RefreshStatus(false)
+	s.running.Wait()
+	if got := commands.Take(); !equalStrings(got, "warn") {
+		t.Errorf("short break: commands=%q", got)
+	}
+}
+
+// TestConflictingActions fires the same action many times at once and checks
+// that they apply one at a time.
+func TestConflictingActions(t *testing.T) {
+	s, _, commands := setup(t)
+	CommandOnStart, CommandOnPause = "start", "pause"
+	h := s.Handler()
+
+	// fire sends target n times at once and returns the responses by status.
+	fire := func(n int, target string) map[int]int {
+		var mu sync.Mutex
+		codes := map[int]int{}
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				code := do(h, "POST", target, nil).Code
+				mu.Lock()
+				codes[code]++
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		s.running.Wait()
+		return codes
+	}
+	count := func(commands []string, name string) int {
+		n := 0
+		for _, c := range commands {
+			if c == name {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Each start toggles the state of the one before.
+	fire(20, "/action/start")
+	got := commands.Take()
+	if s.state != StatePaused || count(got, "start") != 10 || count(got, "pause") != 10 {
+		t.Errorf("20 starts: state=%v commands=%q", s.state, got)
+	}
+
+	// With if=, only the first one applies.
+	do(h, "POST", "/action/stop", nil)
+	commands.Take()
+	codes := fire(20, "/action/start?if="+StateStopped)
+	if got := commands.Take(); codes[http.StatusOK] != 1 || codes[http.StatusConflict] != 19 || s.state != StateRunning || !equalStrings(got, "start") {
+		t.Errorf("20 starts if stopped: codes=%v state=%v commands=%q", codes, s.state, got)
+	}
+	codes = fire(20, "/action/stop?if="+StateRunning)
+	if codes[http.StatusOK] != 1 || s.state != StateStopped || s.mode != ModeWork {
+		t.Errorf("20 stops if running: codes=%v state=%v mode=%v, want a stopped work interval", codes, s.state, s.mode)
+	}
+}
+
+// TestActionsIf checks that every action answers 409 and changes nothing if
+// the timer is not in the state given by if=.
+func TestActionsIf(t *testing.T) {
+	s, _, _ := setup(t)
+	h := s.Handler()
+	do(h, "POST", "/action/start", nil)
+	do(h, "POST", "/action/stopwatch/start", nil)
+
+	for _, target := range []string{
+		"/action/start", "/action/toggle", "/action/pause", "/action/resume",
+		"/action/stop", "/action/reset", "/action/restart", "/action/ack",
+		"/action/skip", "/action/extend", "/action/extend?d=5m", "/action/add?d=5m",
+		"/action/mode?mode=short-break", "/action/set?remaining=5m", "/action/cycle?i=1",
+		"/action/undo", "/action/until?t=14:30", "/action/stopwatch/start",
+		"/action/stopwatch/stop", "/action/stopwatch/lap",
+	} {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		before, undo, watch, laps := s.snapshot(), s.undo, s.watch.start, len(s.watch.laps)
+		rec := do(h, "POST", target+sep+"if="+StateStopped, nil)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("%v: %v %v, want 409", target, rec.Code, rec.Body)
+		}
+		if s.snapshot() != before || s.undo != undo || !s.until.IsZero() || s.watch.start != watch || len(s.watch.laps) != laps {
+			t.Errorf("%v: the action applied", target)
+		}
+	}
+}
+
+// TestPlannedDuration checks that a started interval keeps its duration when
+// the durations change, by the time of day or by PUT /config.
+func TestPlannedDuration(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	HourDurations = []hourDurations{{from: 9*time.Hour + 10*time.Minute, until: 24 * time.Hour, durations: map[Mode]time.Duration{ModeWork: 50 * time.Minute}}}
+	clock.Add(20 * time.Minute)
+	if rec := do(h, "PUT", "/config", strings.NewReader(`{"durations": {"work": "40m"}}`)); rec.Code != http.StatusOK {
+		t.Fatalf("PUT /config: %v %v", rec.Code, rec.Body)
+	}
+	s.RefreshStatus(false)
+	if s.elapsed() != 20*time.Minute || s.remaining() != 5*time.Minute {
+		t.Errorf("elapsed=%v remaining=%v, want 20m and 5m", s.elapsed(), s.remaining())
+	}
+
+	clock.Add(5*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	records, err := s.storage.ListSessions(sessionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Planned != 25*60 {
+		t.Errorf("records=%+v, want one planned for 25m", records)
+	}
+
+	// The next interval starts with the durations of its own start.
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/start", nil)
+	if s.remaining() != 50*time.Minute {
+		t.Errorf("next work interval: remaining=%v, want 50m", s.remaining())
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for _, test := range []struct {
+		str  string
+		want time.Duration
+	}{
+		{"25", 25 * time.Minute},
+		{"25m", 25 * time.Minute},
+		{"90s", 90 * time.Second},
+		{"1h", time.Hour},
+		{"1h30m", 90 * time.Minute},
+		{"20m30s", 20*time.Minute + 30*time.Second},
+		{"1.5h", 90 * time.Minute},
+		{"500ms", 500 * time.Millisecond},
+	} {
+		if d, err := parseDuration(test.str); err != nil || d != test.want {
+			t.Errorf("parseDuration(%q) = %v, %v, want %v", test.str, d, err, test.want)
+		}
+	}
+	for _, str := range []string{"", "0", "0m", "-5m", "-5", "abc", "5x", "m", "1h-30m"} {
+		if d, err := parseDuration(str); err == nil {
+			t.Errorf("parseDuration(%q) = %v, want an error", str, d)
+		}
+	}
+}
+
+func TestActionReset(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(10 * time.Minute)
+	rec := do(h, "POST", "/action/reset", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "25:00" {
+		t.Errorf("POST /action/reset: %v %q, want 25:00", rec.Code, rec.Body)
+	}
+	if s.mode != ModeWork || s.state != StateStopped || s.count != 0 || s.remaining() != 25*time.Minute {
+		t.Errorf("mode=%v state=%v count=%v remaining=%v", s.mode, s.state, s.count, s.remaining())
+	}
+
+	// A paused break stays a break, and the count stays.
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/start", nil)
+	do(h, "POST", "/action/pause", nil)
+	do(h, "POST", "/action/reset", nil)
+	if s.mode != ModeShortBreak || s.state != StateStopped || s.count != 1 || s.remaining() != 5*time.Minute {
+		t.Errorf("mode=%v state=%v count=%v remaining=%v", s.mode, s.state, s.count, s.remaining())
+	}
+
+	if rec := do(h, "GET", "/action/reset", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /action/reset: %v", rec.Code)
+	}
+}
+
+// TestStartDuration checks that /action/start?duration= sets the length of
+// one interval only.
+func TestStartDuration(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+	if rec := do(h, "POST", "/action/start?duration=soon", nil); rec.Code != http.StatusBadRequest || s.state != StateStopped {
+		t.Errorf("duration=soon: %v state=%v, want 400", rec.Code, s.state)
+	}
+	if rec := do(h, "POST", "/action/start?duration=40m", nil); rec.Code != http.StatusOK || rec.Body.String() != "40:00" || s.state != StateRunning {
+		t.Errorf("duration=40m: %v %v state=%v", rec.Code, rec.Body, s.state)
+	}
+	clock.Add(10 * time.Minute)
+	if rec := do(h, "POST", "/action/start?duration=10m", nil); rec.Code != http.StatusConflict || s.state != StateRunning {
+		t.Errorf("duration=10m while running: %v state=%v, want 409", rec.Code, s.state)
+	}
+	do(h, "POST", "/action/pause", nil)
+	if rec := do(h, "POST", "/action/start?duration=10m", nil); rec.Code != http.StatusConflict || s.state != StatePaused {
+		t.Errorf("duration=10m while paused: %v state=%v, want 409", rec.Code, s.state)
+	}
+	do(h, "POST", "/action/resume", nil)
+	clock.Add(30*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	if s.mode != ModeShortBreak || s.count != 1 {
+		t.Fatalf("after 40m: %v count=%v, want the work interval completed", s.mode, s.count)
+	}
+	records, _ := s.storage.ListSessions(sessionFilter{})
+	if len(records) != 1 || records[0].Planned != 40*60 {
+		t.Errorf("records=%+v, want 40m planned", records)
+	}
+
+	// The next work interval has the configured length again.
+	do(h, "POST", "/action/skip", nil)
+	if rec := do(h, "POST", "/action/start", nil); rec.Body.String() != "25:00" {
+		t.Errorf("next work interval: %v, want 25:00", rec.Body)
+	}
+}
+
+// TestActionSkip skips through a full cycle, from the stopped and the running
+// state.
+func TestActionSkip(t *testing.T) {
+	s, _, _ := setup(t)
+	h := s.Handler()
+
+	for _, want := range []struct {
+		mode  Mode
+		count int
+		timer string
+	}{
+		{ModeShortBreak, 1, "05:00"},
+		{ModeWork, 1, "25:00"},
+		{ModeShortBreak, 2, "05:00"},
+		{ModeWork, 2, "25:00"},
+		{ModeShortBreak, 3, "05:00"},
+		{ModeWork, 3, "25:00"},
+		{ModeLongBreak, 4, "15:00"},
+		{ModeWork, 0, "25:00"},
+	} {
+		if want.count%2 == 1 {
+			do(h, "POST", "/action/start", nil)
+		}
+		rec := do(h, "POST", "/action/skip", nil)
+		if rec.Code != http.StatusOK || rec.Body.String() != want.timer {
+			t.Errorf("POST /action/skip to %v: %v %q, want %v", want.mode, rec.Code, rec.Body, want.timer)
+		}
+		if s.mode != want.mode || s.count != want.count || s.state != StateStopped {
+			t.Fatalf("mode=%v count=%v state=%v, want %v and %v", s.mode, s.count, s.state, want.mode, want.count)
+		}
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	s, clock, _ := setup(t)
+	StateFile = filepath.Join(t.TempDir(), "state.json")
+	defer func() { StateFile = "" }()
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(10 * time.Minute)
+	do(h, "POST", "/action/pause", nil)
+	clock.Add(time.Hour)
+	restored := NewServer()
+	if restored.mode != ModeWork || restored.state != StatePaused || restored.remaining() != 15*time.Minute {
+		t.Errorf("paused: mode=%v state=%v remaining=%v, want 15m left", restored.mode, restored.state, restored.remaining())
+	}
+
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/start", nil)
+	clock.Add(2 * time.Minute)
+	restored = NewServer()
+	clock.Add(time.Minute)
+	if restored.mode != ModeShortBreak || restored.state != StateRunning || restored.count != 1 || restored.remaining() != 2*time.Minute {
+		t.Errorf("running: mode=%v state=%v count=%v remaining=%v, want 2m left", restored.mode, restored.state, restored.count, restored.remaining())
+	}
+
+	// A broken file leaves the timer at its defaults.
+	if err := ioutil.WriteFile(StateFile, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	restored = NewServer()
+	if restored.mode != ModeWork || restored.state != StateStopped || restored.count != 0 {
+		t.Errorf("broken file: mode=%v state=%v count=%v", restored.mode, restored.state, restored.count)
+	}
+}
+
+// TestEndCommand runs every interval of a cycle until it ends and checks the
+// command of the mode that ended.
+func TestEndCommand(t *testing.T) {
+	s, clock, commands := setup(t)
+	N = 2
+	h := s.Handler()
+
+	run := func() []string {
+		do(h, "POST", "/action/start", nil)
+		clock.Add(s.duration() + time.Second)
+		s.RefreshStatus(false)
+		s.running.Wait()
+		return commands.Take()
+	}
+
+	Command, CommandWork, CommandShortBreak, CommandLongBreak = "any", "work", "short", "long"
+	for _, want := range []string{"work", "short", "work", "long"} {
+		if got := run(); !equalStrings(got, want) {
+			t.Errorf("commands=%q, want %q", got, want)
+		}
+	}
+
+	// Modes without their own command fall back to -command.
+	CommandWork, CommandLongBreak = "", ""
+	for _, want := range []string{"any", "short", "any", "any"} {
+		if got := run(); !equalStrings(got, want) {
+			t.Errorf("commands=%q, want %q", got, want)
+		}
+	}
+}
+
+func TestEndCommandSelection(t *testing.T) {
+	setup(t)
+	for _, test := range []struct {
+		command, work, short, long string
+		want                       map[Mode]string
+	}{
+		{"", "", "", "", map[Mode]string{ModeWork: "", ModeShortBreak: "", ModeLongBreak: ""}},
+		{"c", "", "", "", map[Mode]string{ModeWork: "c", ModeShortBreak: "c", ModeLongBreak: "c"}},
+		{"c", "w", "s", "l", map[Mode]string{ModeWork: "w", ModeShortBreak: "s", ModeLongBreak: "l"}},
+		{"", "w", "", "l", map[Mode]string{ModeWork: "w", ModeShortBreak: "", ModeLongBreak: "l"}},
+	} {
+		Command, CommandWork, CommandShortBreak, CommandLongBreak = test.command, test.work, test.short, test.long
+		for mode, want := range test.want {
+			if got := endCommand(mode); got != want {
+				t.Errorf("%+v: endCommand(%v) = %q, want %q", test, mode, got, want)
+			}
+		}
+	}
+}
+
+// TestStats completes work intervals on two days and checks /stats.
+func TestStats(t *testing.T) {
+	s, clock, _ := setup(t)
+	StateFile = filepath.Join(t.TempDir(), "state.json")
+	defer func() { StateFile = "" }()
+	h := s.Handler()
+
+	expire := func() {
+		do(h, "POST", "/action/start", nil)
+		clock.Add(s.duration() + time.Second)
+		s.RefreshStatus(false)
+	}
+	expire()
+	do(h, "POST", "/action/skip", nil)
+	expire()
+	do(h, "POST", "/action/skip", nil)
+	// Skipped work intervals are not completed.
+	do(h, "POST", "/action/start", nil)
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/skip", nil)
+
+	clock.Add(24 * time.Hour)
+	expire()
+
+	rec := do(h, "GET", "/stats", nil)
+	want := `{"byDay":{"2024-01-01":2,"2024-01-02":1},"today":1,"total":3}`
+	if rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Errorf("GET /stats: %v %v, want %v", rec.Code, rec.Body, want)
+	}
+
+	// The counts are saved with the state.
+	if rec := do(NewServer().Handler(), "GET", "/stats", nil); rec.Body.String() != want {
+		t.Errorf("GET /stats after a restart: %v, want %v", rec.Body, want)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	s, _, _ := setup(t)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type: %q", ct)
+	}
+
+	events := bufio.NewReader(resp.Body)
+	next := func() string {
+		t.Helper()
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatalf("reading events: %v", err)
+			}
+			if strings.HasPrefix(line, "data: ") {
+				return line
+			}
+		}
+	}
+	if ev := next(); !strings.Contains(ev, `"state":"[S]"`) {
+		t.Errorf("first event: %v", ev)
+	}
+	if rec := do(s.Handler(), "POST", "/action/start", nil); rec.Code != http.StatusOK {
+		t.Fatalf("POST /action/start: %v", rec.Code)
+	}
+	s.RefreshStatus(false) // as on the next tick
+	if ev := next(); !strings.Contains(ev, `"state":"[R]"`) {
+		t.Errorf("event after start: %v", ev)
+	}
+	cancel()
+}
+
+// TestTextFile checks that -text-file holds the shown timer and is only
+// written when it changes.
+func TestTextFile(t *testing.T) {
+	s, clock, _ := setup(t)
+	TextFile, lastFileText = filepath.Join(t.TempDir(), "tomato.txt"), ""
+	defer func() { TextFile, lastFileText = "", "" }()
+	h := s.Handler()
+	read := func() string {
+		t.Helper()
+		data, err := ioutil.ReadFile(TextFile)
+		if err != nil {
+			return err.Error()
+		}
+		return string(data)
+	}
+
+	do(h, "POST", "/action/start", nil)
+	s.RefreshStatus(false)
+	if got := read(); got != "25:00" {
+		t.Errorf("started: %q, want 25:00", got)
+	}
+	clock.Add(90 * time.Second)
+	s.RefreshStatus(false)
+	if got := read(); got != "23:30" {
+		t.Errorf("after 1m30s: %q, want 23:30", got)
+	}
+
+	// While paused the text does not change, so the file is not rewritten.
+	do(h, "POST", "/action/pause", nil)
+	os.Remove(TextFile)
+	clock.Add(time.Minute)
+	s.RefreshStatus(true)
+	if _, err := os.Stat(TextFile); !os.IsNotExist(err) {
+		t.Errorf("paused: the file was rewritten (%v)", err)
+	}
+	do(h, "POST", "/action/resume", nil)
+	clock.Add(time.Second)
+	s.RefreshStatus(false)
+	if got := read(); got != "23:29" {
+		t.Errorf("resumed: %q, want 23:29", got)
+	}
+}
+
+// TestNudge checks that Nudge resends an unchanged timer to BetterTouchTool.
+func TestNudge(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		texts = append(texts, r.URL.Query().Get("text"))
+	}))
+	defer btt.Close()
+
+	s, _, _ := setup(t)
+	URL, UUID = btt.URL, "uuid"
+	defer func() { URL, UUID = "", "" }()
+	forgetLastRequest()
+	h := s.Handler()
+	sent := func() []string {
+		s.requests.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		got := texts
+		texts = nil
+		return got
+	}
+
+	do(h, "POST", "/action/start", nil)
+	s.RefreshStatus(false)
+	if got := sent(); len(got) != 1 {
+		t.Errorf("started: sent %q, want one update", got)
+	}
+	s.RefreshStatus(true)
+	if got := sent(); len(got) != 0 {
+		t.Errorf("refreshed: sent %q, want nothing", got)
+	}
+	for i := 0; i < 2; i++ {
+		s.Nudge()
+		if got := sent(); !equalStrings(got, "25:00") {
+			t.Errorf("nudge %v: sent %q, want the timer again", i+1, got)
+		}
+	}
+}
+
+// TestRequestRetries sends an update to a server that fails the first
+// requests.
+func TestRequestRetries(t *testing.T) {
+	var mu sync.Mutex
+	failures, requests := 0, 0
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests <= failures {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}
+	}))
+	defer btt.Close()
+
+	setup(t)
+	URL, UUID = btt.URL, "uuid"
+	defer func() { URL, UUID, HTTPRetries = "", "", 0 }()
+
+	for _, test := range []struct {
+		failures, retries int
+		ok                bool
+	}{
+		{0, 0, true},
+		{1, 0, false},
+		{2, 2, true},
+		{3, 2, false},
+	} {
+		mu.Lock()
+		failures, requests = test.failures, 0
+		mu.Unlock()
+		HTTPRetries = test.retries
+		forgetLastRequest()
+		text := fmt.Sprintf("%+v", test)
+
+		err := doRequest(requestCtx, nextUpdate(), text, "icon")
+		want := test.failures + 1
+		if !test.ok {
+			want = test.retries + 1
+		}
+		if (err == nil) != test.ok || requests != want || isLastRequest(text, "icon") != test.ok {
+			t.Errorf("%+v: error %v after %v requests, want %v; sent=%v", test, err, requests, want, isLastRequest(text, "icon"))
+		}
+	}
+}
+
+// TestQuietHours checks that in -quiet hours the end command is replaced by
+// -quiet-command and BetterTouchTool is not updated.
+func TestQuietHours(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+	}))
+	defer btt.Close()
+
+	s, clock, commands := setup(t)
+	URL, UUID = btt.URL, "uuid"
+	defer func() { URL, UUID = "", "" }()
+	forgetLastRequest()
+	Command, QuietCommand = "done", "quiet"
+	var err error
+	if Quiet, err = parseQuiet("22:00-08:00"); err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+
+	// run runs the current interval from the given time of day until it
+	// ends, and returns the commands and the number of updates sent.
+	run := func(at time.Time) ([]string, int) {
+		t.Helper()
+		clock.Add(at.Sub(clock.Now()))
+		mu.Lock()
+		requests = 0
+		mu.Unlock()
+		do(h, "POST", "/action/start", nil)
+		clock.Add(s.duration() + time.Second)
+		s.RefreshStatus(false)
+		s.running.Wait()
+		s.requests.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return commands.Take(), requests
+	}
+	day := func(d, h, m int) time.Time {
+		return time.Date(2024, time.January, d, h, m, 0, 0, time.Local)
+	}
+
+	if got, n := run(day(1, 9, 0)); !equalStrings(got, "done") || n == 0 {
+		t.Errorf("at 09:00: commands=%q updates=%v, want the end command and updates", got, n)
+	}
+	if got, n := run(day(1, 22, 30)); !equalStrings(got, "quiet") || n != 0 {
+		t.Errorf("at 22:30: commands=%q updates=%v, want the quiet command and no updates", got, n)
+	}
+	QuietCommand = ""
+	if got, n := run(day(2, 7, 0)); got != nil || n != 0 {
+		t.Errorf("at 07:00 without -quiet-command: commands=%q updates=%v, want none", got, n)
+	}
+	if got, n := run(day(2, 8, 0)); !equalStrings(got, "done") || n == 0 {
+		t.Errorf("at 08:00: commands=%q updates=%v, want the end command and updates", got, n)
+	}
+}
+
+func TestPutConfig(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(5 * time.Minute)
+	rec := do(h, "PUT", "/config", strings.NewReader(`{"n": 3, "durations": {"work": "50m", "short": "10m"}}`))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"work":"50m0s"`) {
+		t.Fatalf("PUT /config: %v %v", rec.Code, rec.Body)
+	}
+	if N != 3 || DurationWork != 50*time.Minute || DurationShortBreak != 10*time.Minute || DurationLongBreak != 15*time.Minute {
+		t.Errorf("n=%v work=%v short=%v long=%v", N, DurationWork, DurationShortBreak, DurationLongBreak)
+	}
+	// The running interval keeps its end.
+	if s.remaining() != 20*time.Minute || s.elapsed() != 5*time.Minute {
+		t.Errorf("running: remaining=%v elapsed=%v, want 20m and 5m", s.remaining(), s.elapsed())
+	}
+	clock.Add(20*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	if s.mode != ModeShortBreak {
+		t.Fatalf("mode=%v, want the work interval ended after 25m", s.mode)
+	}
+	do(h, "POST", "/action/start", nil)
+	if s.remaining() != 10*time.Minute {
+		t.Errorf("short break: remaining=%v, want 10m", s.remaining())
+	}
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/start", nil)
+	if s.remaining() != 50*time.Minute {
+		t.Errorf("next work interval: remaining=%v, want 50m", s.remaining())
+	}
+
+	for _, body := range []string{`{"n": -1}`, `{"durations": {"work": "0"}}`, `{"durations": {"short": "abc"}}`, `{"n": "3"}`, `{`} {
+		if rec := do(h, "PUT", "/config", strings.NewReader(body)); rec.Code != http.StatusBadRequest || N != 3 || DurationWork != 50*time.Minute {
+			t.Errorf("PUT /config %v: %v n=%v work=%v, want 400 and no change", body, rec.Code, N, DurationWork)
+		}
+	}
+}
+
+// TestPatchConfig changes durations, commands and the tick with PATCH
+// /config while an interval runs.
+func TestPatchConfig(t *testing.T) {
+	s, clock, commands := setup(t)
+	h := s.Handler()
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		if name == "/bin/sh" && args[0] == "-n" {
+			return exec.Command(name, args...) // check the syntax for real
+		}
+		return commands.Command(name, args...)
+	}
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(10 * time.Minute)
+	for _, body := range []string{
+		`{"commands": {"nap-command": "say"}}`,
+		`{"commands": {"command": "say 'done"}}`,
+		`{"tick": 5}`,
+		`{"durations": {"work": "soon"}}`,
+		`{"n": -1}`,
+	} {
+		if rec := do(h, "PATCH", "/config", strings.NewReader(body)); rec.Code != http.StatusBadRequest {
+			t.Errorf("PATCH /config %v: %v %v, want 400", body, rec.Code, rec.Body)
+		}
+	}
+	if Command != "" || N != 4 || DurationWork != 25*time.Minute {
+		t.Fatalf("a rejected PATCH changed command=%q n=%v work=%v", Command, N, DurationWork)
+	}
+
+	rec := do(h, "PATCH", "/config", strings.NewReader(`{"n": 2, "durations": {"work": "40m"}, "commands": {"command": "say done", "start-command": "say go"}, "tick": 500}`))
+	if rec.Code != http.StatusOK || N != 2 || DurationWork != 40*time.Minute || Command != "say done" || CommandOnStart != "say go" || Tick != 500*time.Millisecond {
+		t.Fatalf("PATCH /config: %v %v n=%v work=%v command=%q start-command=%q tick=%v", rec.Code, rec.Body, N, DurationWork, Command, CommandOnStart, Tick)
+	}
+	if got := <-s.retick; got != 500*time.Millisecond {
+		t.Errorf("tick sent to the ticker: %v", got)
+	}
+	if s.remaining() != 15*time.Minute {
+		t.Errorf("remaining=%v, want the running interval kept", s.remaining())
+	}
+
+	// The new values apply from the next interval; an empty command
+	// removes it.
+	do(h, "PATCH", "/config", strings.NewReader(`{"commands": {"start-command": ""}}`))
+	clock.Add(15*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/start", nil)
+	s.running.Wait()
+	if got := commands.Take(); !equalStrings(got, "say done") || s.remaining() != 40*time.Minute {
+		t.Errorf("next work interval: commands=%q remaining=%v, want 40m without a start command", got, s.remaining())
+	}
+}
+
+func TestAutoAdvance(t *testing.T) {
+	s, clock, commands := setup(t)
+	AutoAdvance, Command, CommandOnStart = true, "end", "start"
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	commands.Take()
+	clock.Add(25*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	s.running.Wait()
+	if got := commands.Take(); s.mode != ModeShortBreak || s.state != StateRunning || s.count != 1 || !equalStrings(got, "end", "start") {
+		t.Fatalf("mode=%v state=%v count=%v commands=%q", s.mode, s.state, s.count, got)
+	}
+	if s.remaining() != 5*time.Minute {
+		t.Errorf("remaining=%v, want the break started now", s.remaining())
+	}
+
+	// After a long sleep, only one interval advances per refresh.
+	clock.Add(3 * time.Hour)
+	s.RefreshStatus(false)
+	s.running.Wait()
+	if got := commands.Take(); s.mode != ModeWork || s.state != StateRunning || s.count != 1 || s.remaining() != 25*time.Minute || !equalStrings(got, "end", "start") {
+		t.Errorf("after sleep: mode=%v state=%v count=%v remaining=%v commands=%q", s.mode, s.state, s.count, s.remaining(), got)
+	}
+
+	// Without -auto, the timer stops after each interval.
+	AutoAdvance = false
+	clock.Add(25*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	if s.mode != ModeShortBreak || s.state != StateStopped || s.count != 2 {
+		t.Errorf("without -auto: mode=%v state=%v count=%v", s.mode, s.state, s.count)
+	}
+}
+
+// TestAutoBreakWork checks that -auto-break and -auto-work each start only
+// their own kind of interval.
+func TestAutoBreakWork(t *testing.T) {
+	for _, test := range []struct {
+		autoBreak, autoWork bool
+		breakState          string // after the work interval ends
+		workState           string // after the break ends
+	}{
+		{true, false, StateRunning, StateStopped},
+		{false, true, StateStopped, StateRunning},
+	} {
+		s, clock, commands := setup(t)
+		AutoBreak, AutoWork, CommandOnStart = test.autoBreak, test.autoWork, "start"
+		h := s.Handler()
+		do(h, "POST", "/action/start", nil)
+		clock.Add(25*time.Minute + time.Second)
+		s.RefreshStatus(false)
+		if s.mode != ModeShortBreak || s.state != test.breakState {
+			t.Errorf("%+v: after work: mode=%v state=%v", test, s.mode, s.state)
+		}
+		if s.state == StateStopped {
+			do(h, "POST", "/action/start", nil)
+		}
+		clock.Add(5*time.Minute + time.Second)
+		s.RefreshStatus(false)
+		if s.mode != ModeWork || s.state != test.workState {
+			t.Errorf("%+v: after the break: mode=%v state=%v", test, s.mode, s.state)
+		}
+		s.running.Wait()
+		want := []string{"start", "start"}
+		if test.workState == StateRunning {
+			want = append(want, "start")
+		}
+		if got := commands.Take(); !equalStrings(got, want...) {
+			t.Errorf("%+v: commands=%q, want %q", test, got, want)
+		}
+	}
+}
+
+// TestRequireAck checks that with -require-ack an ended interval waits for
+// /action/ack and repeats the end command until then.
+func TestRequireAck(t *testing.T) {
+	s, clock, commands := setup(t)
+	RequireAck, AckReminder, Command = true, time.Minute, "done"
+	h := s.Handler()
+
+	if rec := do(h, "POST", "/action/ack", nil); rec.Code != http.StatusOK || s.state != StateStopped || s.mode != ModeWork {
+		t.Errorf("ack of a stopped timer: %v mode=%v state=%v, want nothing changed", rec.Code, s.mode, s.state)
+	}
+	do(h, "POST", "/action/start", nil)
+	clock.Add(25*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	s.running.Wait()
+	if got := commands.Take(); s.state != StateFinished || s.mode != ModeWork || s.count != 0 || !equalStrings(got, "done") {
+		t.Fatalf("ended: mode=%v state=%v count=%v commands=%q, want a finished work interval", s.mode, s.state, s.count, got)
+	}
+
+	for _, step := range []struct {
+		d    time.Duration
+		want []string
+	}{
+		{30 * time.Second, nil},
+		{30 * time.Second, []string{"done"}},
+		{59 * time.Second, nil},
+		{time.Second, []string{"done"}},
+	} {
+		clock.Add(step.d)
+		s.RefreshStatus(false)
+		s.running.Wait()
+		if got := commands.Take(); !equalStrings(got, step.want...) || s.state != StateFinished {
+			t.Errorf("after %v more: state=%v commands=%q, want %q", step.d, s.state, got, step.want)
+		}
+	}
+
+	rec := do(h, "POST", "/action/ack", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "05:00" || s.state != StateStopped || s.mode != ModeShortBreak || s.count != 1 {
+		t.Errorf("ack: %v %v mode=%v state=%v count=%v, want a stopped short break", rec.Code, rec.Body, s.mode, s.state, s.count)
+	}
+	clock.Add(5 * time.Minute)
+	s.RefreshStatus(false)
+	s.running.Wait()
+	if got := commands.Take(); got != nil {
+		t.Errorf("reminders after the ack: %q", got)
+	}
+}
+
+// TestMinBreak checks that work can not start before -min-break has passed
+// since the previous work interval ended, even with the break skipped.
+func TestMinBreak(t *testing.T) {
+	for _, test := range []struct {
+		minBreak time.Duration
+		percent  int
+		want     string // time left right after the work interval
+	}{
+		{3 * time.Minute, 0, "3m0s"},
+		{0, 50, "2m30s"},
+	} {
+		s, clock, _ := setup(t)
+		MinBreak, MinBreakPercent = test.minBreak, test.percent
+		h := s.Handler()
+		do(h, "POST", "/action/start", nil)
+		clock.Add(25*time.Minute + time.Second)
+		s.RefreshStatus(false)
+		do(h, "POST", "/action/skip", nil)
+
+		for _, target := range []string{"/action/start", "/action/toggle"} {
+			rec := do(h, "POST", target, nil)
+			if want := "Take " + test.want + " more of your break before starting work\n"; rec.Code != http.StatusConflict || rec.Body.String() != want {
+				t.Errorf("%+v: POST %v: %v %q, want 409 %q", test, target, rec.Code, rec.Body, want)
+			}
+		}
+		clock.Add(2 * time.Minute)
+		if rec := do(h, "POST", "/action/start", nil); rec.Code != http.StatusConflict {
+			t.Errorf("%+v: start after 2m: %v, want 409", test, rec.Code)
+		}
+		clock.Add(time.Minute)
+		if rec := do(h, "POST", "/action/start", nil); rec.Code != http.StatusOK || s.mode != ModeWork || s.state != StateRunning {
+			t.Errorf("%+v: start after 3m: %v mode=%v state=%v", test, rec.Code, s.mode, s.state)
+		}
+	}
+
+	// Resetting the cycle starts over without a break to take.
+	s, clock, _ := setup(t)
+	MinBreak = 3 * time.Minute
+	h := s.Handler()
+	do(h, "POST", "/action/start", nil)
+	clock.Add(25*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	do(h, "POST", "/action/reset?cycle=1", nil)
+	if rec := do(h, "POST", "/action/start", nil); rec.Code != http.StatusOK {
+		t.Errorf("start after /action/reset?cycle=1: %v %v", rec.Code, rec.Body)
+	}
+}
+
+// TestReminders manages reminders through /reminders and checks that they
+// run on their own schedule without touching the timer.
+func TestReminders(t *testing.T) {
+	s, clock, commands := setup(t)
+	h := s.Handler()
+	for _, body := range []string{`{"every":"45m"}`, `{"every":"soon","command":"say water"}`, `{`} {
+		if rec := do(h, "POST", "/reminders", strings.NewReader(body)); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /reminders %v: %v, want 400", body, rec.Code)
+		}
+	}
+	if rec := do(h, "POST", "/reminders", strings.NewReader(`{"every":"45m","command":"water"}`)); rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"id":1`) {
+		t.Errorf("POST /reminders: %v %v", rec.Code, rec.Body)
+	}
+	do(h, "POST", "/reminders", strings.NewReader(`{"every":"20m","command":"posture"}`))
+	if rec := do(h, "PUT", "/reminders/2", strings.NewReader(`{"every":"30m","command":"stretch"}`)); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"every":"30m0s"`) {
+		t.Errorf("PUT /reminders/2: %v %v", rec.Code, rec.Body)
+	}
+	if rec := do(h, "GET", "/reminders/3", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /reminders/3: %v, want 404", rec.Code)
+	}
+
+	do(h, "POST", "/action/start", nil)
+	for _, step := range []struct {
+		d    time.Duration
+		want []string
+	}{
+		{30 * time.Minute, []string{"stretch"}},
+		{15 * time.Minute, []string{"water"}},
+		{15 * time.Minute, []string{"stretch"}},
+		{2 * time.Hour, []string{"water", "stretch"}}, // missed runs are skipped
+	} {
+		clock.Add(step.d)
+		s.RefreshStatus(false)
+		s.running.Wait()
+		if got := commands.Take(); !equalStrings(got, step.want...) {
+			t.Errorf("after %v more: commands=%q, want %q", step.d, got, step.want)
+		}
+	}
+	if s.count != 1 {
+		t.Errorf("count=%v, want the work interval counted as usual", s.count)
+	}
+
+	if rec := do(h, "DELETE", "/reminders/1", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE /reminders/1: %v", rec.Code)
+	}
+	var list []reminder
+	if rec := do(h, "GET", "/reminders", nil); json.Unmarshal(rec.Body.Bytes(), &list) != nil || len(list) != 1 || list[0].ID != 2 {
+		t.Errorf("GET /reminders = %v, want reminder 2", rec.Body)
+	}
+}
+
+// TestEyeCommand checks that -eye-command runs after every -eye-every of
+// running work, not while paused, and starts over after a break.
+func TestEyeCommand(t *testing.T) {
+	s, clock, commands := setup(t)
+	EyeCommand, EyeEvery = "look", 20*time.Minute
+	h := s.Handler()
+	step := func(action string, d time.Duration, want ...string) {
+		t.Helper()
+		if action != "" {
+			do(h, "POST", action, nil)
+		}
+		clock.Add(d)
+		s.RefreshStatus(false)
+		s.running.Wait()
+		if got := commands.Take(); !equalStrings(got, want...) {
+			t.Errorf("%v and %v: commands=%q, want %q", action, d, got, want)
+		}
+	}
+
+	step("/action/start", 19*time.Minute)
+	step("", time.Minute, "look")
+	step("/action/pause", 30*time.Minute)
+	step("/action/resume", 5*time.Minute+time.Second) // the work interval ends
+	if s.mode != ModeShortBreak {
+		t.Fatalf("mode=%v, want a break", s.mode)
+	}
+
+	// A skipped break does not start the count over.
+	step("/action/skip", 0)
+	step("/action/start", 14*time.Minute)
+	step("", time.Minute, "look")
+
+	// A break that was taken does.
+	step("/action/skip", 0)
+	step("/action/start", 5*time.Minute+time.Second)
+	step("/action/start", 19*time.Minute)
+	step("", time.Minute, "look")
+}
+
+func TestActionAdd(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+	add := func(d string) string {
+		t.Helper()
+		rec := do(h, "POST", "/action/add?d="+d, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST /action/add?d=%v: %v %v", d, rec.Code, rec.Body)
+		}
+		return rec.Body.String()
+	}
+
+	// Stopped: nothing changes.
+	if got := add("5m"); got != "25:00" || s.state != StateStopped {
+		t.Errorf("stopped: %q state=%v", got, s.state)
+	}
+
+	// Running.
+	do(h, "POST", "/action/start", nil)
+	clock.Add(10 * time.Minute)
+	if got := add("5m"); got != "20:00" {
+		t.Errorf("running +5m: %q", got)
+	}
+	if got := add("-2m30s"); got != "17:30" {
+		t.Errorf("running -2m30s: %q", got)
+	}
+
+	// Paused.
+	do(h, "POST", "/action/pause", nil)
+	if got := add("90s"); got != "19:00" || s.state != StatePaused {
+		t.Errorf("paused +90s: %q state=%v", got, s.state)
+	}
+	if got := add("-1h"); got != "00:00" || s.remaining() != 0 {
+		t.Errorf("paused -1h: %q remaining=%v", got, s.remaining())
+	}
+
+	// Taking more than is left ends a running interval on the next tick.
+	do(h, "POST", "/action/resume", nil)
+	add("10m")
+	if got := add("-1h"); got != "00:00" {
+		t.Errorf("running -1h: %q", got)
+	}
+	clock.Add(100 * time.Millisecond)
+	s.RefreshStatus(false)
+	if s.mode != ModeShortBreak || s.state != StateStopped || s.count != 1 {
+		t.Errorf("running -1h: mode=%v state=%v count=%v", s.mode, s.state, s.count)
+	}
+
+	for _, d := range []string{"", "abc", "0", "--5m"} {
+		if rec := do(h, "POST", "/action/add?d="+d, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /action/add?d=%v: %v, want 400", d, rec.Code)
+		}
+	}
+}
+
+func TestModeIcon(t *testing.T) {
+	s, _, _ := setup(t)
+	red, _ := loadIcon("", "red.png")
+	green, _ := loadIcon("", "green.png")
+	orange, _ := loadIcon("", "orange.png")
+	h := s.Handler()
+
+	for _, test := range []struct {
+		icon3                  string
+		work, short, longBreak string
+	}{
+		{"", red, green, green},
+		{"orange.png", red, green, orange},
+	} {
+		icons, err := loadIcons(options{"icon3": test.icon3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		icons.apply()
+		for _, want := range []struct {
+			mode Mode
+			icon string
+		}{
+			{ModeWork, test.work},
+			{ModeShortBreak, test.short},
+			{ModeLongBreak, test.longBreak},
+		} {
+			do(h, "POST", "/action/mode?mode="+string(want.mode), nil)
+			if s.icon() != want.icon {
+				t.Errorf("-icon3=%q: wrong icon for %v", test.icon3, want.mode)
+			}
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/start", nil)
+	clock.Add(90 * time.Second)
+
+	for _, test := range []struct {
+		format, prefix, want string
+	}{
+		{"", "", "03:30"},
+		{"{timer}", "", "03:30"},
+		{"{timer} 🍅", "", "03:30 🍅"},
+		{"{count}/{n} {mode} {state} {timer}", "", "1/4 short-break [R] 03:30"},
+		{"{timer} {timer}", "", "03:30 03:30"},
+		{"no placeholders", "", "no placeholders"},
+		{"{count}", "🍅 ", "🍅 1"},
+	} {
+		Format, TextPrefix = test.format, test.prefix
+		if err := validateFormat(test.format); err != nil {
+			t.Errorf("%q: %v", test.format, err)
+		}
+		if got := s.widgetText(s.formatTimer()); got != test.want {
+			t.Errorf("%q: %q, want %q", test.format, got, test.want)
+		}
+	}
+	for _, format := range []string{"{bad}", "{timer} {Timer}", "{}"} {
+		if err := validateFormat(format); err == nil {
+			t.Errorf("%q: no error", format)
+		}
+	}
+}
+
+// TestStatusRemaining checks remaining and elapsed in /status in the stopped,
+// running and paused states.
+func TestStatusRemaining(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+	status := func() (remaining, elapsed int) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/status", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var st struct{ Remaining, Elapsed int }
+		if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+			t.Fatalf("GET /status: %v %v", rec.Body, err)
+		}
+		return st.Remaining, st.Elapsed
+	}
+
+	if r, e := status(); r != 1500 || e != 0 {
+		t.Errorf("stopped: remaining=%v elapsed=%v", r, e)
+	}
+	do(h, "POST", "/action/start", nil)
+	clock.Add(100 * time.Second)
+	if r, e := status(); r != 1400 || e != 100 {
+		t.Errorf("running: remaining=%v elapsed=%v", r, e)
+	}
+	do(h, "POST", "/action/pause", nil)
+	clock.Add(time.Hour)
+	if r, e := status(); r != 1400 || e != 100 {
+		t.Errorf("paused: remaining=%v elapsed=%v", r, e)
+	}
+	do(h, "POST", "/action/resume", nil)
+	clock.Add(time.Hour)
+	if r, e := status(); r != 300 || e != 0 {
+		t.Errorf("after the work interval ended: remaining=%v elapsed=%v", r, e)
+	}
+}
+
+// TestStopFromStopped checks that switching mode with /action/stop follows
+// the same sequence as timers that run out.
+func TestStopFromStopped(t *testing.T) {
+	type step struct {
+		mode  Mode
+		count int
+	}
+	walk := func(advance func(s *Server, clock *fakeClock, h http.Handler)) []step {
+		s, clock, _ := setup(t)
+		h := s.Handler()
+		var steps []step
+		for i := 0; i < 2*N+1; i++ {
+			advance(s, clock, h)
+			if s.state != StateStopped {
+				t.Fatalf("state=%v", s.state)
+			}
+			steps = append(steps, step{s.mode, s.count})
+		}
+		return steps
+	}
+	expired := walk(func(s *Server, clock *fakeClock, h http.Handler) {
+		do(h, "POST", "/action/start", nil)
+		clock.Add(s.duration() + time.Second)
+		s.RefreshStatus(false)
+	})
+	stopped := walk(func(s *Server, clock *fakeClock, h http.Handler) {
+		do(h, "POST", "/action/stop", nil)
+	})
+
+	want := []step{
+		{ModeShortBreak, 1}, {ModeWork, 1},
+		{ModeShortBreak, 2}, {ModeWork, 2},
+		{ModeShortBreak, 3}, {ModeWork, 3},
+		{ModeLongBreak, 4}, {ModeWork, 0},
+		{ModeShortBreak, 1},
+	}
+	for i := range want {
+		if expired[i] != want[i] || stopped[i] != want[i] {
+			t.Errorf("step %v: expired=%v stopped=%v, want %v", i, expired[i], stopped[i], want[i])
+		}
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	s, clock, commands := setup(t)
+	CommandOnStart, CommandOnPause, CommandOnResume = "start", "pause", "resume"
+	h := s.Handler()
+	action := func(path string) {
+		t.Helper()
+		if rec := do(h, "POST", path, nil); rec.Code != http.StatusOK {
+			t.Fatalf("POST %v: %v %v", path, rec.Code, rec.Body)
+		}
+		s.running.Wait()
+	}
+
+	// No-ops when stopped.
+	action("/action/pause")
+	action("/action/resume")
+	if got := commands.Take(); s.state != StateStopped || got != nil {
+		t.Errorf("stopped: state=%v commands=%q", s.state, got)
+	}
+
+	action("/action/start")
+	commands.Take()
+	clock.Add(time.Minute)
+	action("/action/resume")
+	if got := commands.Take(); s.state != StateRunning || got != nil {
+		t.Errorf("resume while running: state=%v commands=%q", s.state, got)
+	}
+	action("/action/pause")
+	if got := commands.Take(); s.state != StatePaused || s.remaining() != 24*time.Minute || !equalStrings(got, "pause") {
+		t.Errorf("pause: state=%v remaining=%v commands=%q", s.state, s.remaining(), got)
+	}
+	clock.Add(time.Hour)
+	action("/action/pause")
+	if got := commands.Take(); s.state != StatePaused || s.remaining() != 24*time.Minute || got != nil {
+		t.Errorf("pause while paused: state=%v remaining=%v commands=%q", s.state, s.remaining(), got)
+	}
+	action("/action/resume")
+	if got := commands.Take(); s.state != StateRunning || !s.t.Equal(clock.Now().Add(24*time.Minute)) || !equalStrings(got, "start", "resume") {
+		t.Errorf("resume: state=%v end=%v commands=%q", s.state, s.t, got)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	payloads := make(chan map[string]interface{}, 10)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("%v with %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		payloads <- payload
+	}))
+	defer hook.Close()
+
+	s, clock, _ := setup(t)
+	Webhook = hook.URL
+	defer func() { Webhook = "" }()
+	h := s.Handler()
+
+	end := time.Date(2024, time.January, 1, 9, 25, 1, 0, time.Local).Format(time.RFC3339)
+	for _, test := range []struct {
+		action func()
+		want   map[string]interface{}
+	}{
+		{func() {
+			do(h, "POST", "/action/start", nil)
+			clock.Add(25*time.Minute + time.Second)
+			s.RefreshStatus(false)
+		}, map[string]interface{}{"finished_mode": "work", "mode": "short-break", "count": 1.0, "time": end}},
+		{func() {
+			do(h, "POST", "/action/skip", nil)
+		}, map[string]interface{}{"finished_mode": "short-break", "mode": "work", "count": 1.0, "time": end}},
+	} {
+		test.action()
+		s.requests.Wait()
+		select {
+		case got := <-payloads:
+			if fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("payload %v, want %v", got, test.want)
+			}
+		default:
+			t.Fatalf("no payload, want %v", test.want)
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes, e.g. as the log
+// output.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestCommandErrors runs commands that fail, with and without -async, and
+// checks the error reported for each.
+func TestCommandErrors(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		s, _, _ := setup(t)
+		execCommand = exec.Command // run them for real
+		CommandAsync = async
+		logs := &syncBuffer{}
+		log.SetOutput(logs)
+
+		s.mu.Lock()
+		s.runCommand("exit 3")
+		s.runCommand("true")
+		s.runCommand("terminal-notifier-not-installed -message hi")
+		s.mu.Unlock()
+		s.running.Wait()
+		log.SetOutput(ioutil.Discard)
+
+		out := logs.String()
+		for _, want := range []string{
+			`Failed to execute command "exit 3": exit status 3`,
+			`Failed to execute command "terminal-notifier-not-installed -message hi": exit status 127`,
+			`Note: You may need to download terminal-notifier`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("async=%v: no %q in the log:\n%v", async, want, out)
+			}
+		}
+		if strings.Contains(out, `"true"`) {
+			t.Errorf("async=%v: true failed:\n%v", async, out)
+		}
+		if s.metrics.commands != 3 || s.metrics.commandFailures != 2 {
+			t.Errorf("async=%v: commands=%v failures=%v", async, s.metrics.commands, s.metrics.commandFailures)
+		}
+	}
+}
+
+// TestCheck checks the validation of -check: the shell syntax of the
+// commands and the webhook URL.
+func TestCheck(t *testing.T) {
+	setup(t)
+	execCommand = exec.Command // run /bin/sh -n for real
+
+	Command, CommandOnStart = "say done", "say go"
+	if err := checkCommands(); err != nil {
+		t.Errorf("valid commands: %v", err)
+	}
+	CommandOnStart = "say 'go"
+	if err := checkCommands(); err == nil || !strings.Contains(err.Error(), "Invalid -start-command") {
+		t.Errorf("unterminated quote: %v, want an error for -start-command", err)
+	}
+
+	for _, test := range []struct {
+		webhook string
+		ok      bool
+	}{
+		{"", true},
+		{"https://example.com/hook", true},
+		{"http://127.0.0.1:8080/", true},
+		{"example.com/hook", false},
+		{"ftp://example.com/", false},
+		{"https://", false},
+		{"http://[::1", false},
+	} {
+		Webhook = test.webhook
+		if err := checkWebhook(); (err == nil) != test.ok {
+			t.Errorf("-webhook=%q: %v", test.webhook, err)
+		}
+	}
+	Webhook = ""
+}
+
+func TestToken(t *testing.T) {
+	s, _, _ := setup(t)
+	h := s.Handler()
+	send := func(method, target, auth string) int {
+		req := httptest.NewRequest(method, target, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, test := range []struct {
+		token        string
+		protectReads bool
+		method       string
+		target       string
+		auth         string
+		want         int
+	}{
+		{"", false, "POST", "/action/reset", "", http.StatusOK},
+		{"secret", false, "POST", "/action/reset", "Bearer secret", http.StatusOK},
+		{"secret", false, "POST", "/action/reset", "Bearer wrong", http.StatusUnauthorized},
+		{"secret", false, "POST", "/action/reset", "secret", http.StatusUnauthorized},
+		{"secret", false, "POST", "/action/reset", "", http.StatusUnauthorized},
+		{"secret", false, "PUT", "/config", "", http.StatusUnauthorized},
+		{"secret", false, "GET", "/status", "", http.StatusOK},
+		{"secret", true, "GET", "/status", "", http.StatusUnauthorized},
+		{"secret", true, "GET", "/status", "Bearer secret", http.StatusOK},
+	} {
+		Token, TokenProtectReads = test.token, test.protectReads
+		if got := send(test.method, test.target, test.auth); got != test.want {
+			t.Errorf("%+v: %v", test, got)
+		}
+	}
+
+	// A rejected action changes nothing.
+	Token, TokenProtectReads = "secret", false
+	send("POST", "/action/start", "")
+	if s.state != StateStopped {
+		t.Errorf("state=%v after an unauthorized start", s.state)
+	}
+}
+
+// TestConfigRedacted checks that GET /config shows the commands and targets
+// only with the token.
+func TestConfigRedacted(t *testing.T) {
+	s, _, _ := setup(t)
+	h := s.Handler()
+	Command, Webhook = "say done", "https://hooks.example.com/secret"
+	get := func(auth string) map[string]interface{} {
+		req := httptest.NewRequest("GET", "/config", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var config map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &config); err != nil {
+			t.Fatalf("GET /config: %v %v", rec.Code, rec.Body)
+		}
+		return config
+	}
+
+	for _, test := range []struct {
+		token, auth string
+		shown       bool
+	}{
+		{"", "", false},
+		{"", "Bearer ", false},
+		{"secret", "", false},
+		{"secret", "Bearer wrong", false},
+		{"secret", "Bearer secret", true},
+	} {
+		Token = test.token
+		config := get(test.auth)
+		_, commands := config["commands"]
+		_, targets := config["targets"]
+		if commands != test.shown || targets != test.shown || config["n"] == nil {
+			t.Errorf("-token=%q with %q: %v", test.token, test.auth, config)
+		}
+	}
+	Token = ""
+}
+
+// TestClockJump wakes the timer an hour after it should have ended.
+func TestClockJump(t *testing.T) {
+	for _, test := range []struct {
+		auto, catchUp bool
+		mode          Mode
+		state         string
+		count         int
+		remaining     time.Duration
+	}{
+		// One interval ends and the timer stops.
+		{false, false, ModeShortBreak, StateStopped, 1, 5 * time.Minute},
+		{false, true, ModeShortBreak, StateStopped, 1, 5 * time.Minute},
+		// The next interval starts now.
+		{true, false, ModeShortBreak, StateRunning, 1, 5 * time.Minute},
+		// The intervals that fit in the hour are replayed: work until 09:25,
+		// a break until 09:30, work until 09:55, a break until 10:00, and
+		// work since then.
+		{true, true, ModeWork, StateRunning, 2, 24 * time.Minute},
+	} {
+		s, clock, commands := setup(t)
+		AutoAdvance, CatchUp, Command, CommandOnStart = test.auto, test.catchUp, "end", "start"
+		h := s.Handler()
+
+		do(h, "POST", "/action/start", nil)
+		s.running.Wait()
+		commands.Take()
+		clock.Add(61 * time.Minute)
+		s.RefreshStatus(false)
+		s.running.Wait()
+
+		want := []string{"end"}
+		if test.auto {
+			want = append(want, "start")
+		}
+		if got := commands.Take(); s.mode != test.mode || s.state != test.state || s.count != test.count || s.remaining() != test.remaining || !equalStrings(got, want...) {
+			t.Errorf("auto=%v catch-up=%v: mode=%v state=%v count=%v remaining=%v commands=%q", test.auto, test.catchUp, s.mode, s.state, s.count, s.remaining(), got)
+		}
+		if today := s.completed[dateKey(clock.Now())]; today != test.count {
+			t.Errorf("auto=%v catch-up=%v: completed=%v", test.auto, test.catchUp, today)
+		}
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	var requests int32
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer btt.Close()
+
+	s, clock, commands := setup(t)
+	URL, Command = btt.URL, "end"
+	defer func() {
+		s.requests.Wait()
+		URL = ""
+	}()
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	s.running.Wait()
+	commands.Take()
+	s.requests.Wait()
+	sent := atomic.LoadInt32(&requests)
+	// The timer has run out, but only the ticker or /status may end it.
+	clock.Add(time.Hour)
+	before := s.snapshot()
+
+	rec := do(h, "GET", "/healthz", nil)
+	want := fmt.Sprintf(`{"state":"[R]","uptime":3600,"version":%q}`, version)
+	if rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Errorf("GET /healthz: %v %v, want %v", rec.Code, rec.Body, want)
+	}
+	s.running.Wait()
+	s.requests.Wait()
+	if s.snapshot() != before || commands.Take() != nil || atomic.LoadInt32(&requests) != sent {
+		t.Errorf("GET /healthz changed the timer: mode=%v state=%v", s.mode, s.state)
+	}
+	if rec := do(h, "POST", "/healthz", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("POST /healthz: %v", rec.Code)
+	}
+}
+
+func TestActionSetMode(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+	do(h, "POST", "/action/skip", nil)
+	do(h, "POST", "/action/skip", nil)
+
+	for _, test := range []struct {
+		mode  Mode
+		timer string
+	}{
+		{ModeLongBreak, "15:00"},
+		{ModeShortBreak, "05:00"},
+		{ModeWork, "25:00"},
+		{ModeWork, "25:00"},
+	} {
+		do(h, "POST", "/action/start", nil)
+		clock.Add(time.Minute)
+		rec := do(h, "POST", "/action/mode?mode="+string(test.mode), nil)
+		if rec.Code != http.StatusOK || rec.Body.String() != test.timer {
+			t.Errorf("POST /action/mode?mode=%v: %v %q, want %v", test.mode, rec.Code, rec.Body, test.timer)
+		}
+		if s.mode != test.mode || s.state != StateStopped || s.count != 1 {
+			t.Errorf("mode=%v state=%v count=%v, want a stopped %v and the count kept", s.mode, s.state, s.count, test.mode)
+		}
+	}
+
+	for _, mode := range []string{"", "break", "Work", "long"} {
+		if rec := do(h, "POST", "/action/mode?mode="+mode, nil); rec.Code != http.StatusBadRequest || s.mode != ModeWork {
+			t.Errorf("POST /action/mode?mode=%v: %v mode=%v, want 400", mode, rec.Code, s.mode)
+		}
+	}
+}
+
+// TestActionSetFinished checks that /action/set completes an interval that
+// waits for /action/ack before it sets the time of the next one.
+func TestActionSetFinished(t *testing.T) {
+	s, clock, _ := setup(t)
+	RequireAck = true
+	h := s.Handler()
+	do(h, "POST", "/action/start", nil)
+	clock.Add(25*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	if s.state != StateFinished {
+		t.Fatalf("state=%v, want finished", s.state)
+	}
+
+	rec := do(h, "POST", "/action/set?remaining=3m", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "03:00" || s.mode != ModeShortBreak || s.state != StatePaused || s.count != 1 {
+		t.Errorf("POST /action/set?remaining=3m: %v %v mode=%v state=%v count=%v, want a paused short break", rec.Code, rec.Body, s.mode, s.state, s.count)
+	}
+	records, _ := s.storage.ListSessions(sessionFilter{})
+	if len(records) != 1 || records[0].Outcome != OutcomeCompleted || records[0].Mode != ModeWork {
+		t.Errorf("records=%+v, want the work interval completed", records)
+	}
+}
+
+// TestFlowBreakMinimum checks that a work interval stopped right away with
+// -flowtime earns a break of one minute.
+func TestFlowBreakMinimum(t *testing.T) {
+	for _, test := range []struct {
+		worked time.Duration
+		want   string
+	}{
+		{0, "01:00"},
+		{30 * time.Second, "01:00"},
+		{50 * time.Minute, "10:00"},
+	} {
+		s, clock, _ := setup(t)
+		Flowtime = true
+		h := s.Handler()
+		do(h, "POST", "/action/start", nil)
+		clock.Add(test.worked)
+		rec := do(h, "POST", "/action/stop", nil)
+		if rec.Body.String() != test.want || s.mode != ModeShortBreak || s.duration() != s.flowBreak {
+			t.Errorf("stopped after %v: %v %v, want a break of %v", test.worked, s.mode, rec.Body, test.want)
+		}
+	}
+}
+
+// TestCycle runs through a -cycle twice and checks the mode and length of
+// each interval, and that /action/cycle moves to the matching interval.
+func TestCycle(t *testing.T) {
+	s, clock, _ := setup(t)
+	var err error
+	if Cycle, err = parseCycle("50m work,10m break,25m work,10m break,90m work,30m long-break"); err != nil {
+		t.Fatal(err)
+	}
+	N = cycleWorks()
+	h := s.Handler()
+
+	want := []cycleStep{
+		{ModeWork, 50 * time.Minute}, {ModeShortBreak, 10 * time.Minute}, {ModeWork, 25 * time.Minute},
+		{ModeShortBreak, 10 * time.Minute}, {ModeWork, 90 * time.Minute}, {ModeLongBreak, 30 * time.Minute},
+	}
+	for round := 0; round < 2; round++ {
+		for i, step := range want {
+			if s.mode != step.mode || s.duration() != step.d {
+				t.Fatalf("round %v, interval %v: %v of %v, want %v of %v", round, i, s.mode, s.duration(), step.mode, step.d)
+			}
+			do(h, "POST", "/action/start", nil)
+			clock.Add(step.d + time.Second)
+			s.RefreshStatus(false)
+		}
+		if s.count != 0 || s.workSinceLongBreak != 0 {
+			t.Errorf("round %v: count=%v work since long break=%v, want the cycle started over", round, s.count, s.workSinceLongBreak)
+		}
+	}
+
+	if rec := do(h, "POST", "/action/cycle?i=2", nil); rec.Code != http.StatusOK || rec.Body.String() != "90:00" {
+		t.Errorf("POST /action/cycle?i=2: %v %v, want the third work interval", rec.Code, rec.Body)
+	}
+	do(h, "POST", "/action/skip", nil)
+	if s.mode != ModeLongBreak || s.duration() != 30*time.Minute {
+		t.Errorf("after the third work interval: %v of %v, want the long break", s.mode, s.duration())
+	}
+	if rec := do(h, "POST", "/action/extend", nil); rec.Code != http.StatusConflict {
+		t.Errorf("POST /action/extend: %v, want 409 with -cycle", rec.Code)
+	}
+}
+
+// TestCycleModes checks that /action/mode, /action/set and the state file
+// only take the modes of -cycle.
+func TestCycleModes(t *testing.T) {
+	s, _, _ := setup(t)
+	StateFile = filepath.Join(t.TempDir(), "state.json")
+	defer func() { StateFile = "" }()
+	h := s.Handler()
+	do(h, "POST", "/action/mode?mode=long-break", nil)
+	if s := NewServer(); s.mode != ModeLongBreak {
+		t.Fatalf("restored %v, want the saved long break", s.mode)
+	}
+
+	var err error
+	if Cycle, err = parseCycle("50m work,10m break,50m work,20m break"); err != nil {
+		t.Fatal(err)
+	}
+	N = cycleWorks()
+	if s := NewServer(); s.mode != ModeWork {
+		t.Errorf("restored a %v outside the cycle", s.mode)
+	}
+
+	s = NewServer()
+	h = s.Handler()
+	for _, target := range []string{"/action/mode?mode=long-break", "/action/set?mode=long-break", "/action/set?mode=long-break&remaining=5m"} {
+		rec := do(h, "POST", target, nil)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `Mode "long-break" is not in the cycle`) {
+			t.Errorf("POST %v: %v %v, want 400", target, rec.Code, rec.Body)
+		}
+		if s.mode != ModeWork || s.state != StateStopped {
+			t.Errorf("after POST %v: %v %v, want nothing changed", target, s.mode, stateLabel(s.state))
+		}
+	}
+	for _, test := range []struct{ target, want string }{
+		{"/action/mode?mode=short-break", "10:00"},
+		{"/action/set?mode=work", "50:00"},
+	} {
+		if rec := do(h, "POST", test.target, nil); rec.Code != http.StatusOK || rec.Body.String() != test.want {
+			t.Errorf("POST %v: %v %v, want %v", test.target, rec.Code, rec.Body, test.want)
+		}
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	s, clock, commands := setup(t)
+	DryRun, Command, CommandOnStart = true, "say done", "say go"
+	logs := &syncBuffer{}
+	log.SetOutput(logs)
+	defer log.SetOutput(ioutil.Discard)
+	h := s.Handler()
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(25*time.Minute + time.Second)
+	s.RefreshStatus(false)
+	s.running.Wait()
+
+	if got := commands.Take(); got != nil {
+		t.Errorf("commands=%q, want none in a dry run", got)
+	}
+	for _, want := range []string{`Dry run, not executing command: "say go"`, `Dry run, not executing command: "say done"`} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("no %q in the log:\n%v", want, logs)
+		}
+	}
+	if s.mode != ModeShortBreak || s.count != 1 {
+		t.Errorf("mode=%v count=%v, want the timer to go on", s.mode, s.count)
+	}
+}
+
+// TestPausedTicks counts the updates sent to BetterTouchTool while the timer
+// is paused or stopped.
+func TestPausedTicks(t *testing.T) {
+	var requests int32
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer btt.Close()
+
+	s, clock, _ := setup(t)
+	URL, Format = btt.URL, "{state} {timer}" // show the pause
+	defer func() {
+		s.requests.Wait()
+		URL = ""
+	}()
+	forgetLastRequest()
+	h := s.Handler()
+	// updates runs action followed by a minute of ticks, and returns the
+	// number of updates sent.
+	updates := func(action string) int32 {
+		s.requests.Wait()
+		before := atomic.LoadInt32(&requests)
+		if action != "" {
+			do(h, "POST", action, nil)
+		}
+		for i := 0; i < 600; i++ {
+			clock.Add(100 * time.Millisecond)
+			s.RefreshStatus(false)
+			s.requests.Wait()
+		}
+		return atomic.LoadInt32(&requests) - before
+	}
+
+	if n := updates(""); n != 1 {
+		t.Errorf("stopped: %v updates, want the first one only", n)
+	}
+	if n := updates("/action/start"); n != 60 {
+		t.Errorf("running: %v updates in a minute, want one a second", n)
+	}
+	if n := updates("/action/pause"); n != 1 {
+		t.Errorf("paused: %v updates, want the first one only", n)
+	}
+	if n := updates("/action/stop"); n != 1 {
+		t.Errorf("stopped again: %v updates, want the first one only", n)
+	}
+}
+
+func TestFlagsFromEnv(t *testing.T) {
+	setup(t)
+	t.Setenv("TOMATO_WORK", "50m")
+	t.Setenv("TOMATO_SHORT", "7")
+	t.Setenv("TOMATO_N", "3")
+	t.Setenv("TOMATO_SHORT_COMMAND", "say break over")
+	t.Setenv("TOMATO_AUTO", "true")
+	t.Setenv("TOMATO_LONG", "20m") // given on the command line too
+
+	if err := setFlagsFromEnv(map[string]bool{"long": true}); err != nil {
+		t.Fatal(err)
+	}
+	st, err := currentOptions().parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.apply()
+	if DurationWork != 50*time.Minute || DurationShortBreak != 7*time.Minute || DurationLongBreak != 15*time.Minute || N != 3 || CommandShortBreak != "say break over" || !AutoAdvance {
+		t.Errorf("work=%v short=%v long=%v n=%v short-command=%q auto=%v", DurationWork, DurationShortBreak, DurationLongBreak, N, CommandShortBreak, AutoAdvance)
+	}
+
+	// Values are checked like flags.
+	setup(t)
+	t.Setenv("TOMATO_N", "many")
+	if err := setFlagsFromEnv(nil); err == nil || !strings.Contains(err.Error(), "TOMATO_N") {
+		t.Errorf("TOMATO_N=many: %v", err)
+	}
+	setup(t)
+	os.Unsetenv("TOMATO_N")
+	t.Setenv("TOMATO_WORK", "-5m")
+	if err := setFlagsFromEnv(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := currentOptions().parse(); err == nil {
+		t.Errorf("TOMATO_WORK=-5m: no error")
+	}
+}
+
+// TestConfigInit checks that the file of tomato config init has the options
+// given along with it and reads back to the same values.
+func TestConfigInit(t *testing.T) {
+	setup(t)
+	for name, value := range map[string]string{"work": "50m", "command": `say "done"`, "n": "3"} {
+		flag.Lookup(name).Value.Set(value)
+	}
+	var lines []string
+	for _, line := range strings.Split(initConfig(), "\n") {
+		if !strings.HasPrefix(line, "test.") { // flags of go test
+			lines = append(lines, line)
+		}
+	}
+	config := strings.Join(lines, "\n")
+	for _, want := range []string{"\nwork = \"50m\"\n", "\ncommand = \"say \\\"done\\\"\"\n", "\nn = 3\n", "\n# short = \"5m\"\n", "\n# Work interval\n"} {
+		if !strings.Contains(config, want) {
+			t.Errorf("config init has no %q:\n%v", want, config)
+		}
+	}
+
+	setup(t)
+	if err := setFlagsFromConfig(writeFile(t, "config.toml", config)); err != nil {
+		t.Fatalf("reading the file of config init: %v", err)
+	}
+	st, err := currentOptions().parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.apply()
+	if DurationWork != 50*time.Minute || Command != `say "done"` || N != 3 || DurationShortBreak != 5*time.Minute {
+		t.Errorf("read back work=%v command=%q n=%v short=%v", DurationWork, Command, N, DurationShortBreak)
+	}
+}
+
+// TestStopwatch checks that a -stopwatch work interval counts up without
+// ending on its own, and that stopping it records the time on it.
+func TestStopwatch(t *testing.T) {
+	s, clock, _ := setup(t)
+	Stopwatch = true
+	h := s.Handler()
+
+	if got := s.formatTimer(); got != "00:00" {
+		t.Errorf("stopped: %q, want 00:00", got)
+	}
+	do(h, "POST", "/action/start", nil)
+	for _, step := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Second, "00:10"},
+		{10 * time.Minute, "10:10"},
+		{30 * time.Minute, "40:10"}, // past -work
+		{time.Hour, "1:40:10"},
+	} {
+		clock.Add(step.d)
+		s.RefreshStatus(false)
+		if got := s.formatTimer(); got != step.want || s.mode != ModeWork || s.state != StateRunning {
+			t.Errorf("%v %v %q, want work running %q", s.mode, stateLabel(s.state), got, step.want)
+		}
+	}
+
+	do(h, "POST", "/action/stop", nil)
+	if s.mode != ModeShortBreak || s.state != StateStopped {
+		t.Errorf("after stop: %v %v, want a stopped short break", s.mode, stateLabel(s.state))
+	}
+	if rec := do(h, "GET", "/stats", nil); !strings.Contains(rec.Body.String(), `"today":1`) {
+		t.Errorf("GET /stats: %v, want the stopwatch counted", rec.Body)
+	}
+	rec := do(h, "GET", "/history", nil)
+	var records []historyRecord
+	json.Unmarshal(rec.Body.Bytes(), &records)
+	if len(records) != 1 || records[0].Outcome != OutcomeCompleted || records[0].End.Sub(records[0].Start) != time.Hour+40*time.Minute+10*time.Second {
+		t.Errorf("history: %+v, want the elapsed 1:40:10", records)
+	}
+
+	// Breaks still count down.
+	do(h, "POST", "/action/start", nil)
+	clock.Add(time.Minute)
+	if got := s.formatTimer(); got != "04:00" {
+		t.Errorf("break: %q, want 04:00", got)
+	}
+}
+
+// TestAdHocStopwatch checks that the stopwatch of /action/stopwatch/* takes
+// over the timer while the pomodoro timer keeps running.
+func TestAdHocStopwatch(t *testing.T) {
+	s, clock, _ := setup(t)
+	h := s.Handler()
+	for _, target := range []string{"/action/stopwatch/lap", "/action/stopwatch/stop"} {
+		if rec := do(h, "POST", target, nil); rec.Code != http.StatusConflict {
+			t.Errorf("POST %v without a stopwatch: %v, want 409", target, rec.Code)
+		}
+	}
+
+	do(h, "POST", "/action/start", nil)
+	clock.Add(time.Minute)
+	if rec := do(h, "POST", "/action/stopwatch/start", nil); rec.Body.String() != "00:00" {
+		t.Errorf("POST /action/stopwatch/start: %v, want 00:00", rec.Body)
+	}
+	for _, step := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{time.Minute, "01:00"},
+		{72 * time.Second, "01:12"},
+	} {
+		clock.Add(step.d)
+		if rec := do(h, "POST", "/action/stopwatch/lap", nil); rec.Code != http.StatusOK || rec.Body.String() != step.want {
+			t.Errorf("lap after %v: %v %v, want %v", step.d, rec.Code, rec.Body, step.want)
+		}
+	}
+	if got := s.RefreshStatus(false); got != "02:12" {
+		t.Errorf("timer=%v, want the stopwatch", got)
+	}
+	req := httptest.NewRequest("GET", "/status", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if want := `"stopwatch":{"elapsed":132,"laps":[60,72]}`; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("GET /status = %v, want %v", rec.Body, want)
+	}
+
+	clock.Add(3 * time.Second)
+	if rec := do(h, "POST", "/action/stopwatch/stop", nil); rec.Body.String() != "02:15" {
+		t.Errorf("POST /action/stopwatch/stop: %v, want 02:15", rec.Body)
+	}
+	if got := s.RefreshStatus(false); got != "21:45" || s.mode != ModeWork || s.state != StateRunning {
+		t.Errorf("after the stopwatch: %v %v %v, want the work interval still running", got, s.mode, s.state)
+	}
+	if records, _ := s.storage.ListSessions(sessionFilter{}); len(records) != 0 {
+		t.Errorf("records=%+v, want the stopwatch not recorded", records)
+	}
+}
+
+// TestActionUntil checks the countdown of /action/until to a time of day.
+func TestActionUntil(t *testing.T) {
+	s, clock, commands := setup(t)
+	Command, AlarmCommand = "done", ""
+	h := s.Handler()
+
+	if rec := do(h, "POST", "/action/until?t=9:3", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("t=9:3: %v, want 400", rec.Code)
+	}
+	do(h, "POST", "/action/until?t=08:00", nil)
+	if want := time.Date(2024, time.January, 2, 8, 0, 0, 0, time.Local); !s.until.Equal(want) {
+		t.Errorf("t=08:00 at 09:00: until=%v, want tomorrow", s.until)
+	}
+	if rec := do(h, "POST", "/action/until", nil); rec.Body.String() != "25:00" || !s.until.IsZero() {
+		t.Errorf("without t: %v until=%v, want the countdown cancelled", rec.Body, s.until)
+	}
+
+	if rec := do(h, "POST", "/action/until?t=09:30", nil); rec.Body.String() != "30:00" {
+		t.Errorf("t=09:30: %v, want 30:00", rec.Body)
+	}
+	clock.Add(10 * time.Minute)
+	if got := s.RefreshStatus(false); got != "20:00" || s.state != StateStopped {
+		t.Errorf("at 09:10: %v %v, want the countdown beside the stopped timer", got, s.state)
+	}
+	s.running.Wait()
+	if got := commands.Take(); got != nil {
+		t.Errorf("commands=%q before the time", got)
+	}
+
+	clock.Add(20 * time.Minute)
+	got := s.RefreshStatus(false)
+	s.running.Wait()
+	if cmds := commands.Take(); got != "25:00" || !s.until.IsZero() || !equalStrings(cmds, "done") {
+		t.Errorf("at 09:30: %v until=%v commands=%q, want the end command and the timer back", got, s.until, cmds)
+	}
+
+	// -alarm-command replaces the end command.
+	AlarmCommand = "alarm"
+	do(h, "POST", "/action/until?t=09:31", nil)
+	clock.Add(time.Minute)
+	s.RefreshStatus(false)
+	s.running.Wait()
+	if got := commands.Take(); !equalStrings(got, "alarm") {
+		t.Errorf("commands=%q, want the alarm command", got)
+	}
+}
+
+// TestOncePerSecond ticks every 20ms through a one minute work interval and
+// checks that BetterTouchTool gets one update per displayed second, and one
+// for the break that follows.
+func TestOncePerSecond(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	btt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		texts = append(texts, r.URL.Query().Get("text"))
+		mu.Unlock()
+	}))
+	defer btt.Close()
+
+	s, clock, _ := setup(t)
+	URL, DurationWork = btt.URL, time.Minute
+	defer func() {
+		s.requests.Wait()
+		URL = ""
+	}()
+	forgetLastRequest()
+	do(s.Handler(), "POST", "/action/start", nil)
+	for i := 0; i <= 3001; i++ {
+		s.RefreshStatus(false)
+		s.requests.Wait()
+		clock.Add(20 * time.Millisecond)
+	}
+
+	if len(texts) != 62 || texts[61] != "05:00" {
+		t.Fatalf("%v updates %q, want 01:00 to 00:00 and then the break", len(texts), texts)
+	}
+	for i := 0; i <= 60; i++ {
+		if want := formatTimer(time.Duration(60-i)*time.Second, ":"); texts[i] != want {
+			t.Errorf("update %v: %q, want %q", i, texts[i], want)
+		}
+	}
+}
+
+// TestStateLabels swaps the labels of the stopped and running states to
+// check that only the output changes, not the transitions.
+func TestStateLabels(t *testing.T) {
+	s, clock, _ := setup(t)
+	LabelStopped, LabelPaused, LabelRunning = "[R]", "⏸", "[S]"
+	Format = "{state} {timer}"
+	StateFile = filepath.Join(t.TempDir(), "state.json")
+	defer func() { StateFile = "" }()
+	h := s.Handler()
+	// status returns the widget text and the state of GET /status.
+	status := func() (string, string) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/status", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var st struct{ State string }
+		if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+			t.Fatalf("GET /status: %v %v", rec.Body, err)
+		}
+		return s.widgetText(s.formatTimer()), st.State
+	}
+
+	for _, step := range []struct {
+		action, text, state string
+	}{
+		{"", "[R] 25:00", "[R]"},
+		{"/action/toggle", "[S] 25:00", "[S]"},
+		{"/action/pause", "⏸ 24:00", "⏸"},
+		{"/action/toggle", "[S] 24:00", "[S]"},
+		{"/action/stop", "[R] 25:00", "[R]"},
+	} {
+		if step.action != "" {
+			if rec := do(h, "POST", step.action, nil); rec.Code != http.StatusOK {
+				t.Fatalf("POST %v: %v %v", step.action, rec.Code, rec.Body)
+			}
+		}
+		if text, state := status(); text != step.text || state != step.state {
+			t.Errorf("after %q: %q %q, want %q %q", step.action, text, state, step.text, step.state)
+		}
+		clock.Add(time.Minute)
+	}
+
+	// if= takes the labels.
+	if rec := do(h, "POST", "/action/start?if=[S]", nil); rec.Code != http.StatusConflict {
+		t.Errorf("start if running: %v %v", rec.Code, rec.Body)
+	}
+	if rec := do(h, "POST", "/action/start?if=[R]", nil); rec.Code != http.StatusOK || s.state != StateRunning {
+		t.Errorf("start if stopped: %v %v", rec.Code, rec.Body)
+	}
+	// The state file keeps the states, not the labels.
+	LabelStopped, LabelPaused, LabelRunning = StateStopped, StatePaused, StateRunning
+	if s := NewServer(); s.state != StateRunning {
+		t.Errorf("restored state %q, want %q", s.state, StateRunning)
+	}
+}
+
+func TestFormatTimer(t *testing.T) {
+	for _, test := range []struct {
+		d    time.Duration
+		sep  string
+		want string
+	}{
+		{-time.Second, ":", "00:00"},
+		{0, ":", "00:00"},
+		{999 * time.Millisecond, ":", "00:00"},
+		{59 * time.Second, ":", "00:59"},
+		{59 * time.Second, "·", "00·59"},
+		{25 * time.Minute, ":", "25:00"},
+		{59*time.Minute + 59*time.Second, "·", "59·59"},
+		{time.Hour + 5*time.Second, ":", "60:05"},
+		{99*time.Minute + 59*time.Second, ":", "99:59"},
+		{100 * time.Minute, ":", "1:40:00"},
+		{100 * time.Minute, " ", "1 40 00"},
+		{2 * time.Hour, "·", "2·00·00"},
+		{12*time.Hour + 3*time.Minute + 4*time.Second, ":", "12:03:04"},
+		{100*time.Hour - time.Second, ":", "99:59:59"},
+		{1000 * time.Hour, ":", "99:59:59"},
+	} {
+		if got := formatTimer(test.d, test.sep); got != test.want {
+			t.Errorf("formatTimer(%v, %q) = %q, want %q", test.d, test.sep, got, test.want)
+		}
+	}
+}
+
+// TestLongInterval runs a two hour work interval through the stopped,
+// running and paused states.
+func TestLongInterval(t *testing.T) {
+	s, clock, _ := setup(t)
+	DurationWork = 2 * time.Hour
+	h := s.Handler()
+	for _, step := range []struct {
+		action string
+		want   string
+	}{
+		{"", "2:00:00"},
+		{"/action/start", "1:59:00"},
+		{"/action/pause", "1:59:00"},
+		{"/action/resume", "1:58:00"},
+	} {
+		if step.action != "" {
+			do(h, "POST", step.action, nil)
+		}
+		clock.Add(time.Minute)
+		if rec := do(h, "GET", "/status", nil); rec.Body.String() != step.want {
+			t.Errorf("after %q: %q, want %q", step.action, rec.Body, step.want)
+		}
+	}
+	clock.Add(19 * time.Minute)
+	if rec := do(h, "GET", "/status", nil); rec.Body.String() != "99:00" {
+		t.Errorf("under 100 minutes: %q, want 99:00", rec.Body)
+	}
+}
+
+// TestGoal completes work intervals past -goal on two days.
+func TestGoal(t *testing.T) {
+	s, clock, commands := setup(t)
+	h := s.Handler()
+	status := func() map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/status", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var st map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+			t.Fatalf("GET /status: %v %v", rec.Body, err)
+		}
+		return st
+	}
+	if st := status(); st["goal"] != nil || st["goalProgress"] != nil || st["goal_reached"] != nil {
+		t.Errorf("without -goal: %v", st)
+	}
+
+	Goal, CommandOnGoal = 2, "say goal"
+	// work completes a work interval and skips the break.
+	work := func() {
+		do(h, "POST", "/action/start", nil)
+		clock.Add(s.duration() + time.Second)
+		s.RefreshStatus(false)
+		do(h, "POST", "/action/skip", nil)
+		s.running.Wait()
+	}
+	goals := func() (n int) {
+		for _, c := range commands.Take() {
+			if c == CommandOnGoal {
+				n++
+			}
+		}
+		return n
+	}
+	for i, want := range []struct {
+		progress float64
+		reached  bool
+		goals    int
+	}{
+		{1, false, 0},
+		{2, true, 1},
+		{3, true, 0},
+	} {
+		work()
+		st := status()
+		if st["goal"] != float64(2) || st["goalProgress"] != want.progress || st["goal_reached"] != want.reached {
+			t.Errorf("after %v intervals: %v", i+1, st)
+		}
+		if n := goals(); n != want.goals {
+			t.Errorf("after %v intervals: the goal command ran %v times, want %v", i+1, n, want.goals)
+		}
+	}
+
+	// The next day starts over.
+	clock.Add(24 * time.Hour)
+	if st := status(); st["goalProgress"] != float64(0) || st["goal_reached"] != false {
+		t.Errorf("the next day: %v", st)
+	}
+	work()
+	work()
+	if n := goals(); n != 1 {
+		t.Errorf("the next day: the goal command ran %v times, want once", n)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML needed for -config: key = value pairs
+// with strings, numbers and booleans, [table] and [[array.of.tables]]
+// headers with dotted names, and # comments. It returns the document in the
+// shape encoding/json would decode it to, with numbers as json.Number.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	table := root
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		var err error
+		switch {
+		case strings.HasPrefix(line, "[["):
+			if !strings.HasSuffix(line, "]]") {
+				return nil, fmt.Errorf("line %v: invalid table header", n)
+			}
+			table, err = tomlArrayTable(root, line[2:len(line)-2])
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %v: invalid table header", n)
+			}
+			table, err = tomlTable(root, line[1:len(line)-1])
+		default:
+			err = tomlKeyValue(table, line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", n, err)
+		}
+	}
+	return root, scanner.Err()
+}
+
+// stripComment removes a # comment that is not inside a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == '"' && c == '\\':
+			i++ // skip the escaped character
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// tomlTable returns the table for a dotted header such as presets.writing,
+// creating it as needed.
+func tomlTable(root map[string]interface{}, header string) (map[string]interface{}, error) {
+	table := root
+	for _, name := range strings.Split(header, ".") {
+		name, err := tomlKey(name)
+		if err != nil {
+			return nil, err
+		}
+		switch v := table[name].(type) {
+		case nil:
+			t := map[string]interface{}{}
+			table[name] = t
+			table = t
+		case map[string]interface{}:
+			table = v
+		case []interface{}:
+			// Continue in the last table of an array of tables.
+			table = v[len(v)-1].(map[string]interface{})
+		default:
+			return nil, fmt.Errorf("%q is not a table", name)
+		}
+	}
+	return table, nil
+}
+
+// tomlArrayTable appends a table to the array named by header and returns
+// it.
+func tomlArrayTable(root map[string]interface{}, header string) (map[string]interface{}, error) {
+	i := strings.LastIndex(header, ".")
+	parent := root
+	if i >= 0 {
+		var err error
+		if parent, err = tomlTable(root, header[:i]); err != nil {
+			return nil, err
+		}
+	}
+	name, err := tomlKey(header[i+1:])
+	if err != nil {
+		return nil, err
+	}
+	t := map[string]interface{}{}
+	switch v := parent[name].(type) {
+	case nil:
+		parent[name] = []interface{}{t}
+	case []interface{}:
+		parent[name] = append(v, t)
+	default:
+		return nil, fmt.Errorf("%q is not an array of tables", name)
+	}
+	return t, nil
+}
+
+func tomlKeyValue(table map[string]interface{}, line string) error {
+	i := strings.Index(line, "=")
+	if i < 0 {
+		return fmt.Errorf("expected key = value")
+	}
+	key, err := tomlKey(line[:i])
+	if err != nil {
+		return err
+	}
+	if _, ok := table[key]; ok {
+		return fmt.Errorf("duplicate key %q", key)
+	}
+	value, err := tomlValue(strings.TrimSpace(line[i+1:]))
+	if err != nil {
+		return fmt.Errorf("invalid value for %q: %v", key, err)
+	}
+	table[key] = value
+	return nil
+}
+
+// tomlKey parses a bare or quoted key.
+func tomlKey(str string) (string, error) {
+	str = strings.TrimSpace(str)
+	if strings.HasPrefix(str, "\"") {
+		return strconv.Unquote(str)
+	}
+	if strings.HasPrefix(str, "'") && strings.HasSuffix(str, "'") && len(str) >= 2 {
+		return str[1 : len(str)-1], nil
+	}
+	if str == "" || strings.IndexFunc(str, func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_')
+	}) >= 0 {
+		return "", fmt.Errorf("invalid key %q", str)
+	}
+	return str, nil
+}
+
+func tomlValue(str string) (interface{}, error) {
+	switch {
+	case str == "true":
+		return true, nil
+	case str == "false":
+		return false, nil
+	case strings.HasPrefix(str, "\""):
+		return strconv.Unquote(str)
+	case strings.HasPrefix(str, "'"):
+		if len(str) < 2 || !strings.HasSuffix(str, "'") {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		return str[1 : len(str)-1], nil
+	}
+	str = strings.Replace(str, "_", "", -1)
+	if _, err := strconv.ParseFloat(str, 64); err != nil {
+		return nil, fmt.Errorf("%q is not a string, number or boolean", str)
+	}
+	return json.Number(str), nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	for _, test := range []struct {
+		toml, want string
+	}{
+		{``, `{}`},
+		{`work = "50m"`, `{"work":"50m"}`},
+		{`work = '50m'`, `{"work":"50m"}`},
+		{`n = 4`, `{"n":4}`},
+		{`ratio = 0.2`, `{"ratio":0.2}`},
+		{`n = 1_000`, `{"n":1000}`},
+		{`auto = true` + "\n" + `notify = false`, `{"auto":true,"notify":false}`},
+		{`"quoted key" = 1`, `{"quoted key":1}`},
+		{`'literal key' = 1`, `{"literal key":1}`},
+		{`format = "say \"done\"\tnow\\"`, `{"format":"say \"done\"\tnow\\"}`},
+		{`format = "caf\u00e9"`, `{"format":"café"}`},
+		{`format = 'C:\path'`, `{"format":"C:\\path"}`},
+		{`command = "echo # not a comment" # a comment`, `{"command":"echo # not a comment"}`},
+		{`command = 'echo # not a comment'`, `{"command":"echo # not a comment"}`},
+		{`command = "say \"#1\""`, `{"command":"say \"#1\""}`},
+		{"# a comment\n\n  work = \"50m\"  \n", `{"work":"50m"}`},
+		{"[presets.writing]\nwork = \"50m\"\n[presets.reading]\nwork = \"30m\"",
+			`{"presets":{"reading":{"work":"30m"},"writing":{"work":"50m"}}}`},
+		{"work = \"50m\"\n[weekdays.\"saturday\"]\nwork = \"25m\"",
+			`{"weekdays":{"saturday":{"work":"25m"}},"work":"50m"}`},
+		{"[[hours]]\nuntil = \"12:00\"\n[hours.durations]\nwork = \"25m\"\n[[hours]]\nfrom = \"14:00\"\n[hours.durations]\nwork = \"45m\"",
+			`{"hours":[{"durations":{"work":"25m"},"until":"12:00"},{"durations":{"work":"45m"},"from":"14:00"}]}`},
+		{"[[a.b]]\nx = 1\n[[a.b]]\nx = 2", `{"a":{"b":[{"x":1},{"x":2}]}}`},
+	} {
+		doc, err := parseTOML([]byte(test.toml))
+		if err != nil {
+			t.Errorf("%q: %v", test.toml, err)
+			continue
+		}
+		got, _ := json.Marshal(doc)
+		if string(got) != test.want {
+			t.Errorf("%q: got %s, want %s", test.toml, got, test.want)
+		}
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, test := range []struct {
+		toml, want string
+	}{
+		{`work`, `line 1: expected key = value`},
+		{"work = \"50m\"\nwork = \"25m\"", `line 2: duplicate key "work"`},
+		{"\n\nwork = \"50m", `line 3: invalid value for "work"`},
+		{`work = '50m`, `line 1: invalid value for "work": unterminated string`},
+		{`work = 50m`, `line 1: invalid value for "work": "50m" is not a string, number or boolean`},
+		{`work = [1, 2]`, `line 1: invalid value for "work"`},
+		{`bad key = 1`, `line 1: invalid key "bad key"`},
+		{` = 1`, `line 1: invalid key ""`},
+		{"# comment\n[presets", `line 2: invalid table header`},
+		{"[[hours]", `line 1: invalid table header`},
+		{"work = \"50m\"\n[work]", `line 2: "work" is not a table`},
+		{"[hours]\n[[hours]]", `line 2: "hours" is not an array of tables`},
+		{"[a..b]", `line 1: invalid key ""`},
+	} {
+		_, err := parseTOML([]byte(test.toml))
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("%q: error %v, want %v", test.toml, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+var (
+	// AlarmCommand runs when the countdown of ActionUntil is over, instead
+	// of Command.
+	AlarmCommand string
+
+	// IconAlarm is the icon shown during the countdown.
+	IconAlarm, IconAlarmData string
+)
+
+// nextClockTime returns the next time after now at the local time of day
+// given as "15:04" or "15:04:05".
+func nextClockTime(str string, now time.Time) (time.Time, error) {
+	clock, err := parseClock(str)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t := time.Date(now.Year(), now.Month(), now.Day(),
+		int(clock/time.Hour), int(clock%time.Hour/time.Minute), int(clock%time.Minute/time.Second), 0, now.Location())
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// ActionUntil starts a one-shot countdown to the local time given by the t
+// parameter, e.g. t=14:30, beside the pomodoro timer. A time that has passed
+// today is taken as tomorrow. Without t, the countdown is cancelled.
+func (s *Server) ActionUntil(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var until time.Time
+	if str := r.FormValue("t"); str != "" {
+		var err error
+		until, err = nextClockTime(str, timeNow())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	s.until = until
+	fmt.Fprint(w, s.refreshStatus(true))
+}
+
+// untilLeft returns the time left in the countdown of ActionUntil, or 0.
+func (s *Server) untilLeft() time.Duration {
+	if s.until.IsZero() {
+		return 0
+	}
+	return s.until.Sub(timeNow())
+}
+
+// checkUntil runs -alarm-command and ends the countdown once its time is
+// reached. It reports whether it did.
+func (s *Server) checkUntil() bool {
+	if s.until.IsZero() || timeNow().Before(s.until) {
+		return false
+	}
+	log.Printf("Countdown to %v is over", s.until.Format("15:04:05"))
+	s.until = time.Time{}
+	command := AlarmCommand
+	if command == "" {
+		command = Command
+	}
+	s.runCommand(quietCommand(command))
+	return true
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// watch is a stopwatch for ad-hoc timing. It runs beside the pomodoro timer
+// and takes over the outputs while it runs. It is guarded by Server.mu.
+type watch struct {
+	start time.Time   // zero unless running
+	laps  []time.Time // end of each lap
+}
+
+func (w *watch) running() bool {
+	return !w.start.IsZero()
+}
+
+// elapsed returns the time since the watch was started.
+func (w *watch) elapsed() time.Duration {
+	if !w.running() {
+		return 0
+	}
+	return timeNow().Sub(w.start)
+}
+
+// lapTimes returns the length of each lap.
+func (w *watch) lapTimes() []time.Duration {
+	out := make([]time.Duration, len(w.laps))
+	prev := w.start
+	for i, t := range w.laps {
+		out[i] = t.Sub(prev)
+		prev = t
+	}
+	return out
+}
+
+// ActionStopwatchStart starts the stopwatch. Starting a running stopwatch
+// changes nothing.
+func (s *Server) ActionStopwatchStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if !s.watch.running() {
+		s.watch = watch{start: timeNow()}
+	}
+	fmt.Fprint(w, s.refreshStatus(true))
+}
+
+// ActionStopwatchStop stops the stopwatch and responds with the time on it.
+// The outputs show the pomodoro timer again.
+func (s *Server) ActionStopwatchStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if !s.watch.running() {
+		http.Error(w, "The stopwatch is not running", http.StatusConflict)
+		return
+	}
+	str := formatTimer(s.watch.elapsed(), SepColon)
+	s.watch = watch{}
+	s.refreshStatus(true)
+	fmt.Fprint(w, str)
+}
+
+// ActionStopwatchLap ends the current lap and responds with its time.
+func (s *Server) ActionStopwatchLap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stateChanged(w, r) {
+		return
+	}
+
+	if !s.watch.running() {
+		http.Error(w, "The stopwatch is not running", http.StatusConflict)
+		return
+	}
+	s.watch.laps = append(s.watch.laps, timeNow())
+	laps := s.watch.lapTimes()
+	fmt.Fprint(w, formatTimer(laps[len(laps)-1], SepColon))
+}
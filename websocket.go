@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// WebSocket opcodes, see RFC 6455.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// wsMaxMessage bounds the size of messages read from clients.
+const wsMaxMessage = 64 << 10
+
+var wsAction = regexp.MustCompile(`^[a-z]+$`)
+
+// wsMessage is a command sent by a WebSocket client.
+type wsMessage struct {
+	Action string            `json:"action"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// WebSocket pushes the status JSON like Events and accepts messages such as
+// {"action":"start"} or {"action":"add","params":{"d":"5m"}}, which run the
+// matching /action endpoint. The result of an action is sent back as
+// {"action":"start","result":"24:59"} or {"action":"start","error":"..."}.
+func (s *Server) WebSocket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+	// Actions arrive over a GET request, so check the token here.
+	if Token != "" && !hasToken(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+		http.Error(w, "Expected a WebSocket upgrade", http.StatusBadRequest)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket unsupported", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		log.Printf("Error while upgrading to WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	sum := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %v\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+	if err := rw.Flush(); err != nil {
+		return
+	}
+
+	ch := make(chan statusEvent, 1)
+	s.mu.Lock()
+	s.refreshStatus(false)
+	ch <- statusEvent{s.seq, s.formatStatusJSON()}
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}()
+
+	// Only this goroutine writes to the connection. The reader passes
+	// messages and pings on through in.
+	type frame struct {
+		opcode  byte
+		payload []byte
+	}
+	in := make(chan frame)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(in)
+		for {
+			opcode, payload, err := readWSMessage(rw.Reader)
+			if err != nil {
+				if err != io.EOF {
+					log.Printf("Error while reading from WebSocket: %v", err)
+				}
+				return
+			}
+			select {
+			case in <- frame{opcode, payload}:
+			case <-stop:
+				return
+			}
+			if opcode == wsClose {
+				return
+			}
+		}
+	}()
+
+	var last int64 = -1
+	for {
+		var err error
+		select {
+		case <-s.closed:
+			writeWSFrame(rw.Writer, wsClose, nil)
+			return
+		case ev := <-ch:
+			if ev.seq <= last {
+				continue
+			}
+			last = ev.seq
+			err = writeWSFrame(rw.Writer, wsText, ev.data)
+		case f, ok := <-in:
+			if !ok {
+				return
+			}
+			switch f.opcode {
+			case wsClose:
+				writeWSFrame(rw.Writer, wsClose, nil)
+				return
+			case wsPing:
+				err = writeWSFrame(rw.Writer, wsPong, f.payload)
+			case wsText:
+				err = writeWSFrame(rw.Writer, wsText, s.wsReply(f.payload))
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// wsReply runs the action requested by a client message and returns the
+// reply.
+func (s *Server) wsReply(data []byte) []byte {
+	var msg wsMessage
+	reply := map[string]string{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		reply["error"] = fmt.Sprintf("Invalid message: %v", err)
+	} else if !wsAction.MatchString(msg.Action) {
+		reply["error"] = fmt.Sprintf("Invalid action %q", msg.Action)
+	} else {
+		reply["action"] = msg.Action
+		path := "/action/" + msg.Action
+		if len(msg.Params) > 0 {
+			q := url.Values{}
+			for k, v := range msg.Params {
+				q.Set(k, v)
+			}
+			path += "?" + q.Encode()
+		}
+		code, body := s.action(path)
+		if code/100 == 2 {
+			reply["result"] = body
+		} else {
+			reply["error"] = strings.TrimSpace(body)
+			if code == http.StatusNotFound {
+				reply["error"] = fmt.Sprintf("Unknown action %q", msg.Action)
+			}
+		}
+	}
+	out, _ := json.Marshal(reply)
+	return out
+}
+
+// readWSMessage reads a complete message from a client, joining fragments.
+// Control frames are returned as they arrive.
+func readWSMessage(r *bufio.Reader) (byte, []byte, error) {
+	var message []byte
+	var messageOpcode byte
+	for {
+		fin, opcode, payload, err := readWSFrame(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		if opcode >= wsClose {
+			return opcode, payload, nil
+		}
+		if opcode != wsContinuation {
+			messageOpcode = opcode
+		}
+		message = append(message, payload...)
+		if len(message) > wsMaxMessage {
+			return 0, nil, errors.New("message too large")
+		}
+		if fin {
+			return messageOpcode, message, nil
+		}
+	}
+}
+
+func readWSFrame(r *bufio.Reader) (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(r, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	opcode = head[0] & 0x0f
+	if head[1]&0x80 == 0 {
+		err = errors.New("unmasked frame from client")
+		return
+	}
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > wsMaxMessage {
+		err = errors.New("message too large")
+		return
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(r, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// writeWSFrame writes an unfragmented, unmasked frame and flushes it.
+func writeWSFrame(w *bufio.Writer, opcode byte, payload []byte) error {
+	w.WriteByte(0x80 | opcode)
+	switch n := len(payload); {
+	case n < 126:
+		w.WriteByte(byte(n))
+	case n <= 0xffff:
+		w.WriteByte(126)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(127)
+		binary.Write(w, binary.BigEndian, uint64(n))
+	}
+	w.Write(payload)
+	return w.Flush()
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h[name] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wsClient is the client end of a WebSocket connection in tests.
+type wsClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialWS opens a WebSocket connection to /ws on srv.
+func dialWS(t *testing.T, srv *httptest.Server) *wsClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	io.WriteString(conn, "GET /ws HTTP/1.1\r\n"+
+		"Host: tomato\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The accept key of the example in RFC 6455.
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("upgrade: %v %v", resp.Status, resp.Header)
+	}
+	return &wsClient{t, conn, r}
+}
+
+// send sends a masked frame, as clients must.
+func (c *wsClient) send(opcode byte, payload []byte) {
+	c.t.Helper()
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// next returns the opcode and payload of the next frame from the server.
+func (c *wsClient) next() (byte, []byte) {
+	c.t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		c.t.Fatal(err)
+	}
+	n := int(head[1] & 0x7f)
+	if n == 126 {
+		var ext [2]byte
+		io.ReadFull(c.r, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		c.t.Fatal(err)
+	}
+	return head[0] & 0x0f, payload
+}
+
+// nextJSON returns the next text frame decoded.
+func (c *wsClient) nextJSON() map[string]interface{} {
+	c.t.Helper()
+	opcode, payload := c.next()
+	var v map[string]interface{}
+	if err := json.Unmarshal(payload, &v); opcode != wsText || err != nil {
+		c.t.Fatalf("frame %x %s: %v", opcode, payload, err)
+	}
+	return v
+}
+
+func TestWebSocket(t *testing.T) {
+	s, _, _ := setup(t)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	c := dialWS(t, srv)
+	defer c.conn.Close()
+
+	if st := c.nextJSON(); st["state"] != "[S]" || st["mode"] != "work" {
+		t.Errorf("first frame: %v", st)
+	}
+	c.send(wsText, []byte(`{"action":"start"}`))
+	if reply := c.nextJSON(); reply["action"] != "start" || reply["result"] != "25:00" {
+		t.Errorf("reply to start: %v", reply)
+	}
+	s.RefreshStatus(false) // as on the next tick
+	if st := c.nextJSON(); st["state"] != "[R]" {
+		t.Errorf("frame after start: %v", st)
+	}
+
+	for msg, want := range map[string]string{
+		`{"action":"nope"}`:     `Unknown action "nope"`,
+		`{"action":"../x"}`:     `Invalid action "../x"`,
+		`{"action":"add"}`:      "",
+		`not json`:              "Invalid message",
+		`{"action":"set_mode"}`: `Invalid action "set_mode"`,
+	} {
+		c.send(wsText, []byte(msg))
+		reply := c.nextJSON()
+		if got, _ := reply["error"].(string); got == "" || !strings.HasPrefix(got, want) {
+			t.Errorf("%v: %v, want an error %q", msg, reply, want)
+		}
+	}
+
+	c.send(wsPing, []byte("hi"))
+	if opcode, payload := c.next(); opcode != wsPong || string(payload) != "hi" {
+		t.Errorf("ping: %x %q", opcode, payload)
+	}
+	c.send(wsClose, nil)
+	if opcode, _ := c.next(); opcode != wsClose {
+		t.Errorf("close: %x", opcode)
+	}
+	// The server forgets the client once it is gone.
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		n := len(s.subscribers)
+		s.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("%v subscribers after the close", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebSocketRejects(t *testing.T) {
+	s, _, _ := setup(t)
+	if rec := do(s.Handler(), "GET", "/ws", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("without an upgrade: %v", rec.Code)
+	}
+	Token = "secret"
+	defer func() { Token = "" }()
+	if rec := do(s.Handler(), "GET", "/ws", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without the token: %v", rec.Code)
+	}
+}
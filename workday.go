@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// parseWeekdayList parses a comma separated list of days such as
+// "mon,tue,wed".
+func parseWeekdayList(str string) (map[time.Weekday]bool, error) {
+	days := map[time.Weekday]bool{}
+	for _, name := range strings.Split(str, ",") {
+		day, ok := parseWeekday(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("Invalid weekday %q", name)
+		}
+		days[day] = true
+	}
+	return days, nil
+}
+
+// nextStart returns the next time after now at the time of day at on one of
+// days.
+func nextStart(at string, days map[time.Weekday]bool, now time.Time) (time.Time, error) {
+	t, err := nextClockTime(at, now)
+	if err != nil {
+		return t, err
+	}
+	for i := 0; i < 7 && !days[t.Weekday()]; i++ {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// startDaily starts a work interval at the time of day at on each of days
+// until done is closed.
+func (s *Server) startDaily(at string, days map[time.Weekday]bool, done chan struct{}) {
+	for {
+		next, _ := nextStart(at, days, timeNow())
+		timer := time.NewTimer(next.Sub(timeNow()))
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.ScheduledStart()
+		}
+	}
+}
+
+// ScheduledStart starts a work interval if the timer is stopped. A stopped
+// break is skipped; a running or paused interval is left alone.
+func (s *Server) ScheduledStart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.remember(s.snapshot())
+
+	if s.state != StateStopped {
+		log.Printf("Timer is %v, not starting the scheduled work interval", stateLabel(s.state))
+		return
+	}
+	if s.mode != ModeWork {
+		s.mode = ModeWork
+		s.flowBreak = 0
+	}
+	log.Print("Starting the scheduled work interval")
+	s.toggle()
+	s.refreshStatus(true)
+}
@@ -25,7 +25,7 @@ func bindata_read(data []byte, name string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-var _green_png = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x44\x09\xbb\xf6\x89\x50\x4e\x47\x0d\x0a\x1a\x0a\x00\x00\x00\x0d\x49\x48\x44\x52\x00\x00\x00\x22\x00\x00\x00\x22\x08\x06\x00\x00\x00\x3a\x47\x0b\xc2\x00\x00\x04\x19\x69\x43\x43\x50\x6b\x43\x47\x43\x6f\x6c\x6f\x72\x53\x70\x61\x63\x65\x47\x65\x6e\x65\x72\x69\x63\x52\x47\x42\x00\x00\x38\x8d\x8d\x55\x5d\x68\x1c\x55\x14\x3e\xbb\x73\x67\x23\x24\xce\x53\x6c\x34\x85\x74\xa8\x3f\x0d\x25\x0d\x93\x56\x34\xa1\xb4\xba\x7f\xdd\xdd\x36\x6e\x96\x49\x36\xda\x22\xe8\x64\xf6\xee\xce\x98\xc9\xce\x38\x33\xbb\xfd\xa1\x4f\x45\x50\x7c\x31\xea\x9b\x14\xc4\xbf\xb7\x80\x20\x28\xf5\x0f\xdb\x3e\xb4\x2f\x95\x0a\x25\xda\xd4\x20\x28\x3e\xb4\xf8\x83\x50\xe8\x8b\xa6\xeb\x99\x3b\x33\x99\x69\xba\xb1\xde\x65\xee\x7c\xf3\x9d\xef\x9e\x7b\xee\xb9\x67\xef\x05\xe8\xb9\xaa\x58\x96\x91\x14\x01\x16\x9a\xae\x2d\x17\x32\xe2\x73\x87\x8f\x88\x3d\x2b\x90\x84\x87\xa0\x17\x06\xa1\x57\x51\x1d\x2b\x5d\xa9\x4c\x02\x36\x4f\x0b\x77\xb5\x5b\xdf\x43\xc2\x7b\x5f\xd9\xd5\xdd\xfe\x9f\xad\xb7\x46\x1d\x15\x20\x71\x1f\x62\xb3\xe6\xa8\x0b\x88\x8f\x01\xf0\xa7\x55\xcb\x76\x01\x7a\xfa\x91\x1f\x3f\xea\x5a\x1e\xf6\x62\xe8\xb7\x31\x40\xc4\x2f\x7a\xb8\xe1\x63\xd7\xc3\x73\x3e\x7e\x8d\x69\x66\xe4\x2c\xe2\xd3\x88\x05\x55\x53\x6a\x88\x97\x10\x8f\xcc\xc5\xf8\x46\x0c\xfb\x31\xb0\xd6\x5f\xa0\x4d\x6a\xeb\xaa\xe8\xe5\xa2\x62\x9b\x75\xdd\xa0\xb1\x70\xef\x61\xfe\x9f\x6d\xc1\x68\x85\xf3\x6d\xc3\xa7\xcf\x99\x9f\x3e\x84\xef\x61\x5c\xfb\x2b\x35\x25\xe7\xe1\x51\xc4\x4b\xaa\x92\x9f\x46\xfc\x08\xe2\x6b\x6d\x7d\xb6\x1c\xe0\xdb\x96\x9b\x91\x11\x3f\x06\x90\xdc\xde\x9a\xaf\xa6\x11\xef\x44\x5c\xac\xdb\x07\xaa\xbe\x9f\xa4\xad\xb5\x8a\x21\x7e\xe7\x84\x36\xf3\x2c\xe2\x2d\x88\xcf\x37\xe7\xca\x53\xc1\xd8\xab\xaa\x93\xc5\x9c\xc1\x76\xc4\xb7\x35\x5a\xf2\xf2\x3b\x04\xc0\x89\xba\x5b\x9a\xf1\xc7\x72\xfb\x6d\x53\x9e\xf2\xe7\xe5\xea\x35\x9a\xcb\x7b\x79\x44\xfc\xfa\xbc\x79\x48\xf6\x7d\x72\x9f\x39\xed\xe9\x7c\xe8\xf3\x84\x96\x2d\x07\xfc\xa5\x97\x94\x83\x15\xc4\x83\x88\x7f\xa1\x46\x41\xf6\xe7\xe2\xfe\xb1\xdc\x4a\x10\x03\x19\x6a\x1a\xe5\x49\x7f\x2e\x92\xa3\x0e\x5b\x2f\xe3\x5d\x6d\xa6\xe8\xcf\x4b\x0c\x17\x37\xd4\x1f\x4b\x16\xeb\xfa\x81\x52\xa0\xff\x44\xb3\x8b\x72\x80\xaf\x59\x06\xab\x51\x8c\x8d\x4f\xda\x2d\xb9\xea\xeb\xf9\x51\xc5\xce\x17\x7c\x9f\x7c\x85\x36\xab\x81\x7f\xbe\x0d\xb3\x09\x05\x28\x98\x30\x87\xbd\x0a\x4d\x58\x03\x11\x64\x28\x40\x06\xdf\x16\xd8\x68\xa9\x83\x0e\x06\x32\x14\xad\x14\x19\x8a\x5f\xa1\x66\x17\x1b\xe7\xc0\x3c\xf2\x3a\xb4\x99\xcd\xc1\xbe\xc2\x94\xfe\xc8\xc8\x5f\x83\xf9\xb8\xce\xb4\x2a\x64\x87\x3e\x82\x16\xb2\x1a\xfc\x8e\xac\x16\xd3\x65\xf1\xab\x85\x5c\x63\x13\x3f\x7e\x2c\x37\x02\x3f\x26\x19\x20\x12\xd9\x83\xcf\x5e\x32\x49\xf6\x91\x71\x32\x01\x22\x79\x8a\x3c\x4d\xf6\x93\x1c\xb2\x13\x64\xef\xfa\xd8\x4a\x6c\x45\x5e\x3c\x37\xd6\xfd\xbc\x8c\x33\x52\xa6\x9b\x45\xdd\x39\xb4\xbb\xa0\x60\xff\x33\x2a\x4c\x5c\x53\xd7\xac\x2c\x0e\xb6\x86\x23\xcb\x29\xfb\x05\x5d\xbd\xfc\xc6\x5f\xb1\x5c\xe9\x2c\x37\x51\xb6\xe2\x19\x9d\xba\x57\xce\xf9\x5f\xf9\xeb\xfc\x32\xf6\x2b\xfc\x6a\xa4\xe0\x7f\xe4\x57\xf1\xb7\x72\xc7\x5a\xcc\xbb\xb2\x4c\xc3\xec\x6c\x58\x73\x77\x55\x1a\x6d\x06\xe3\x16\xf0\xd1\x99\xc5\x89\xc5\x1d\xf3\x71\xf1\xe4\x57\x0f\x46\x7e\x96\xc9\x99\xe7\xaf\xf4\x5d\x3c\x59\x6f\x2e\x0e\x46\xac\x97\x05\xfa\x6a\xf9\x56\x19\x4e\x8d\x44\xac\xf4\x83\xf4\x87\xb4\x2c\xbd\x27\x7d\x28\xfd\xc6\xbd\xcd\x7d\xca\x7d\xcd\x7d\xce\x7d\xc1\x5d\x02\x91\x3b\xcb\x9d\xe3\xbe\xe1\x2e\x70\x1f\x73\x5f\xc6\xf6\x6a\xf3\x1a\x5a\xdf\x7b\x16\x79\x18\xb7\x67\xe9\x96\x6b\xac\x4a\x21\x23\x6c\x15\x1e\x16\x72\xc2\x36\xe1\x51\x61\x32\xf2\x27\x0c\x08\x63\x42\x51\xd8\x81\x96\xad\xeb\xfb\x16\x9f\x2f\x9e\x3d\x1d\x0e\x63\x1f\xe6\xa7\xfb\x5c\xbe\x2e\x56\x01\x89\xfb\xb1\x02\xf4\x4d\xfe\x55\x55\x54\xe9\x70\x94\x29\x1d\x56\x6f\x4d\x38\xbe\x41\x13\x8c\x24\x43\x64\x8c\x94\x36\x54\xf7\xb8\x57\xf3\xa1\x22\x95\x4f\xe5\x52\x69\x10\x53\x3b\x53\x13\xa9\xb1\xd4\x41\x0f\x87\xb3\xa6\x76\xa0\x6d\x02\xfb\xfc\x1d\xd5\xa9\x6e\xb2\x52\xea\xd2\x63\xde\x7d\x02\x59\xd3\x3a\x6e\xeb\x0d\xcd\x15\x77\x4b\xd2\x93\x62\x1a\xaf\x36\x2a\x96\x9a\xea\xe8\x88\xa8\x18\x86\xc8\x4c\x8e\x68\x53\x87\xda\x6d\x5a\x1b\x05\xef\xde\xf4\x8f\xf4\x9b\x32\xbb\x0f\x13\x5b\x2e\x47\x9c\xfb\x0c\xc0\xbe\x3f\xf1\xec\xfb\x2e\xe2\x8e\xb4\x00\x96\x1c\x80\x81\xc7\x23\x6e\x18\xcf\xca\x07\xde\x05\x38\xf3\x84\xda\xb2\xdb\xc1\x1d\x91\x48\x7c\x0b\xe0\xd4\xf7\xec\xf6\xbf\xfa\x32\x78\x7e\xfd\xd4\xe9\xdc\xc4\x73\xac\xe7\x2d\x80\xb5\x37\x3b\x9d\xbf\xdf\xef\x74\xd6\x3e\x40\xff\xab\x00\x67\x8d\x7f\x01\xa0\x9f\x7c\x55\x03\x5c\x0b\xef\x00\x00\x04\xe6\x49\x44\x41\x54\x58\x09\xc5\x58\xcd\x6f\x1b\x55\x10\xff\xed\xdb\x5d\xaf\x37\x09\x89\x93\x4d\x82\xb1\x49\xdd\xa4\x82\xb4\x4d\x8a\x8a\x88\x48\x69\x4f\x70\x81\x03\xa2\x52\x25\xca\x11\x09\x15\x90\xf8\x17\x90\x10\x37\x4e\x1c\x10\x1c\x2b\x21\x21\x4e\x9c\x43\xab\x8a\x88\x54\x70\x0a\xa2\x12\x0d\x6d\x53\x27\xe4\xab\x49\xed\x7c\xd9\xf1\x66\xe3\xcf\xfd\x62\xde\x3a\x35\x76\xd6\x0e\x29\x22\xf6\x28\x9b\xbc\xaf\x79\xf3\x9b\x99\x37\xef\xcd\x44\x00\xe0\xd2\xd7\x90\x3a\xfa\xdb\x30\xf0\x6e\x0c\x52\xbb\x08\xd7\x71\x61\xa6\x8a\xd0\xe3\x7b\xd8\x9d\xdd\x45\x6e\x27\x57\x97\xaf\xf3\x44\x27\x7a\xc7\xba\x11\x1c\x68\x87\x24\x33\x18\x7f\x19\x58\xfe\x71\x15\xae\xe9\xd4\x5d\xcf\x07\xa5\x86\x33\xfb\x13\xa5\xac\x09\xa5\x4b\x84\x36\xa6\xc1\x2e\x3a\x10\x04\x20\xf2\xa6\x80\x7c\xba\x80\xc7\x13\xeb\x48\x4e\x25\x61\xe5\x4d\x6f\xb5\x40\x42\x87\xde\x89\x21\x72\x39\x0c\x51\x91\x01\x02\x2e\x05\x45\x14\x75\xf3\x50\x10\x9c\x59\xa4\xef\x73\xde\x68\x44\x36\x69\x91\xb9\x6b\x40\xee\x55\x10\x0c\x07\x68\x43\x17\xae\xed\x40\x54\x45\xd2\x5a\x83\xfa\xac\x0a\x67\xc3\x42\x57\x6f\x1b\xa2\x6f\x47\x10\xbd\x32\x00\xd7\x22\xcd\xe9\x13\x44\x86\xdd\xa5\x2c\x16\xae\x2f\xc0\xdc\x07\xdb\x48\x0e\xe9\x77\xb8\x6b\xd4\xf6\x00\x94\x90\x8a\xd0\x89\x0e\x0c\xbe\x17\x83\xd3\xcd\x60\xb9\x65\x13\x73\xeb\x70\x2b\xa8\x0e\x83\xa6\xaa\x58\xcb\x1b\x04\x92\x80\xba\x02\x04\x06\x64\x17\xb3\x88\x7f\x3d\x8f\x5c\xaa\xbe\x0b\xab\x41\xd5\x05\x22\xab\x32\x22\xa3\x7d\x78\xf1\x8d\x28\xa2\x2f\x69\x68\xeb\x57\x11\xa4\x33\x62\x92\x75\x0a\xd9\x12\x76\xf4\x02\x0c\xd7\x44\x89\x5c\xc5\x1c\xb2\x8e\x22\x42\x21\xe1\x79\x38\x1e\x08\x59\x10\xd1\xdd\x19\x84\x90\xb2\x90\xf8\x63\x0b\x8c\x10\x67\x92\x79\x6c\xc6\x77\x90\x5a\xd4\x91\x37\x0a\xd5\x18\xbc\xb6\x0f\xc8\xc9\xf1\x28\x2e\x7c\x30\x8c\xc8\xcb\x1a\x48\x2f\x38\x24\xdc\x21\x57\x38\x24\x88\x13\xdf\x94\xb7\x6c\xd7\xa2\x39\xc1\x03\x02\x45\x20\x1f\x4b\x64\x29\x9b\x80\xd0\x1a\x46\x7d\x32\x09\xe1\x01\x0b\x90\x69\x88\xb8\xf5\x1c\xcb\x45\x6a\x59\xc7\xcc\x0f\x8b\xb8\x7f\x73\x05\xa5\x2a\x77\x55\x80\x48\xb2\x84\xf1\xf7\xcf\x60\xfc\xda\x69\xda\xcc\x85\x55\x6a\x1c\x4c\x0e\x07\xe1\xb9\xc7\x25\x01\x82\xb7\x9e\xf3\x08\x24\x9c\x79\x5f\xe3\x18\x90\x24\x02\x49\x16\x5c\xf8\x39\x81\xc9\x2f\xee\xc0\xd8\x8f\x3c\x0f\x08\xdf\xec\xd2\x87\xe7\x70\xe1\xe3\x33\x30\x29\x4a\x9e\x68\xef\xa9\x72\x4c\xbf\x14\x72\xf5\xe2\xed\x04\x26\x3e\x9b\x46\x29\x67\xc2\xb3\xdb\xe0\x6b\x11\xbc\x7a\x6d\xb8\x69\x20\xb8\x6e\xc5\xac\x8d\xa1\xd7\xa3\x18\x79\x2b\xe6\xa9\xca\x82\x6d\x12\x2e\x7e\x74\x96\xc2\xcd\x6d\x8a\x25\xaa\x0d\x6c\x17\x6c\x9c\xbf\x7a\x0a\xea\x33\x41\xb0\xf0\xd9\x3e\xf4\x9f\x0e\x51\x44\x34\x3e\x13\xd5\xcc\xff\x67\xdb\x22\xe5\x7b\x62\x9d\xd0\x86\xba\xc0\x62\xe3\xfd\x74\xba\xf9\x51\x69\x0d\x09\xb2\x80\xf0\xa8\x06\xd6\xfb\x42\xc8\x0b\xab\xd6\xc0\xa0\x90\xa6\xbb\x51\x3b\xd5\x09\xd6\xa1\x29\xe5\x5e\xab\x90\xd0\x53\xd0\xfd\x9c\x4a\x51\x43\x71\xdd\x4a\xe2\xf7\x8f\x12\x0a\x80\xd1\x35\xd4\x4a\x1c\x15\xd9\xcc\x29\xf0\x27\xbc\x99\x60\xea\x47\x27\xdb\x4b\x13\x10\x7a\x1b\x5a\x49\x36\x87\x90\x59\x31\xc0\x9a\x7a\x4e\x0e\x28\x4d\x46\x28\x64\x4a\x60\x5b\x73\x7a\x2b\x8d\x01\x46\xc9\x53\x26\x91\x05\xdb\x9c\xcb\x50\xaa\x57\x6a\x0a\x18\x9b\x72\x98\x83\xc4\x13\xa8\xed\x78\x9a\x5c\x93\x34\x90\x5a\xc8\x41\xa4\x1b\xee\xb8\x49\x14\xfc\xe9\x81\x5d\xb4\xb1\xfe\x20\x03\xc6\x73\xc9\xf9\xa9\x55\x48\xfb\x09\xcc\xf1\x82\xa9\x55\x96\x2b\xbf\x11\xcf\x20\xfd\x48\x2f\xa7\x01\xf3\x94\xa4\xe4\x76\x29\x27\xe0\x69\x54\x13\x49\x0a\x88\x88\xdf\x5a\xf5\x32\x35\x2f\x1f\x49\xaf\xe9\x88\xdf\x78\x04\x49\x2d\xa7\x75\xb5\x58\xea\xc7\x7d\xed\x9a\x43\x7a\x94\x66\xd6\xcb\xcf\x19\x79\x49\x4f\xe4\x30\x37\x99\xf0\x98\x2b\x92\xef\x7c\x3f\x07\x63\xa3\x08\x91\x17\x18\x35\xc4\xad\x44\x85\x95\x6d\xd5\x8c\x1e\xb9\xe3\xbd\xec\x7e\x4b\x4b\x8a\x84\xe9\xeb\x0f\x61\xa4\xf6\xbc\xad\x2a\x40\x32\xeb\x06\x7e\xf9\x6a\x06\x22\x99\xcb\x4f\x02\x64\xd1\x7f\xd0\xfc\xeb\xea\x8d\xf8\x41\x04\xda\x28\x67\x9d\x7c\x8c\xfb\xb7\x96\x2a\x0c\x15\x20\x7c\xe4\xe1\x4f\x4b\x98\xfe\x76\x0e\x4a\xc7\x7f\x15\x5a\xd9\xb7\x61\x43\x26\xf7\x6f\xc6\x75\x4c\x7d\x79\x97\x2a\xc7\x7f\xac\xec\xab\xf4\x1e\xff\xb9\x8d\x60\x40\xc2\xf3\x63\x7d\x54\xb1\x51\xb1\xd4\x70\x4b\x3e\x71\x70\xd6\xaf\x7d\x35\x3b\xb7\xc4\x16\x85\xea\x8d\x4f\xa7\xb1\x93\xdc\xad\x9e\xf2\x5e\xbb\x83\xbb\xd1\x95\xcf\xf0\xca\xd5\x61\x5c\xa4\xac\x9e\x51\xed\x6a\x15\xca\x95\x5d\x0d\xe7\x53\x74\x64\x0a\x53\x81\xf6\x9c\x9d\x58\xc1\xaf\xdf\xdc\x83\x91\xce\xfa\xb8\xcb\x27\xd1\x37\x5c\x1e\x88\x9e\xeb\xc7\xa5\x4f\x46\x30\xc0\xad\x43\xa5\xa4\x5d\xb2\x8f\x9c\x60\xf3\xab\x40\x0c\x10\x00\x3a\xac\x1b\xf7\x74\xfc\xfe\xdd\x03\xc4\x6f\xaf\x79\x35\x50\x3d\x71\x87\x02\xe1\x0c\xbc\xfc\x8c\x8d\x85\x31\x7a\xf9\x24\xa2\xe7\x7b\x11\x0c\xf1\x2a\x9f\x7e\x78\x58\x52\xb5\xcf\x2b\x3b\x8f\xe8\xf1\xe2\x15\xde\x93\xfc\x37\x9f\x2e\x22\x39\xb3\x8d\xd9\x9b\x6b\x58\xf9\x2d\x89\xfc\xde\xe1\xcf\xc8\xbf\x02\xd9\x17\xe3\xfd\xd1\x06\x42\x94\xe8\xf6\x20\x3c\xd2\x8d\x9e\xc1\x0e\xb4\xf7\xa8\x08\xd0\x7f\x05\x4a\x74\x96\x4c\xaa\x67\x8d\xf5\x02\xd2\xcb\x7b\x74\x65\xef\x60\x7b\x3e\x03\x1e\x89\x47\xa5\xa7\x02\x52\xbd\xa9\x40\xda\x07\x55\xc5\xab\x6d\xb9\xdb\x8a\x45\xb3\x26\x0a\xaa\xd7\x1e\xa5\xfd\x37\xaa\x63\xe7\x4e\xad\x72\x60\x70\x00\x00\x00\x00\x49\x45\x4e\x44\xae\x42\x60\x82\x01\x00\x00\xff\xff\x51\x21\x41\xd4\x44\x09\x00\x00")
+var _green_png = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x44\x09\xbb\xf6\x89\x50\x4e\x47\x0d\x0a\x1a\x0a\x00\x00\x00\x0d\x49\x48\x44\x52\x00\x00\x00\x22\x00\x00\x00\x22\x08\x06\x00\x00\x00\x3a\x47\x0b\xc2\x00\x00\x04\x19\x69\x43\x43\x50\x6b\x43\x47\x43\x6f\x6c\x6f\x72\x53\x70\x61\x63\x65\x47\x65\x6e\x65\x72\x69\x63\x52\x47\x42\x00\x00\x38\x8d\x8d\x55\x5d\x68\x1c\x55\x14\x3e\xbb\x73\x67\x23\x24\xce\x53\x6c\x34\x85\x74\xa8\x3f\x0d\x25\x0d\x93\x56\x34\xa1\xb4\xba\x7f\xdd\xdd\x36\x6e\x96\x49\x36\xda\x22\xe8\x64\xf6\xee\xce\x98\xc9\xce\x38\x33\xbb\xfd\xa1\x4f\x45\x50\x7c\x31\xea\x9b\x14\xc4\xbf\xb7\x80\x20\x28\xf5\x0f\xdb\x3e\xb4\x2f\x95\x0a\x25\xda\xd4\x20\x28\x3e\xb4\xf8\x83\x50\xe8\x8b\xa6\xeb\x99\x3b\x33\x99\x69\xba\xb1\xde\x65\xee\x7c\xf3\x9d\xef\x9e\x7b\xee\xb9\x67\xef\x05\xe8\xb9\xaa\x58\x96\x91\x14\x01\x16\x9a\xae\x2d\x17\x32\xe2\x73\x87\x8f\x88\x3d\x2b\x90\x84\x87\xa0\x17\x06\xa1\x57\x51\x1d\x2b\x5d\xa9\x4c\x02\x36\x4f\x0b\x77\xb5\x5b\xdf\x43\xc2\x7b\x5f\xd9\xd5\xdd\xfe\x9f\xad\xb7\x46\x1d\x15\x20\x71\x1f\x62\xb3\xe6\xa8\x0b\x88\x8f\x01\xf0\xa7\x55\xcb\x76\x01\x7a\xfa\x91\x1f\x3f\xea\x5a\x1e\xf6\x62\xe8\xb7\x31\x40\xc4\x2f\x7a\xb8\xe1\x63\xd7\xc3\x73\x3e\x7e\x8d\x69\x66\xe4\x2c\xe2\xd3\x88\x05\x55\x53\x6a\x88\x97\x10\x8f\xcc\xc5\xf8\x46\x0c\xfb\x31\xb0\xd6\x5f\xa0\x4d\x6a\xeb\xaa\xe8\xe5\xa2\x62\x9b\x75\xdd\xa0\xb1\x70\xef\x61\xfe\x9f\x6d\xc1\x68\x85\xf3\x6d\xc3\xa7\xcf\x99\x9f\x3e\x84\xef\x61\x5c\xfb\x2b\x35\x25\xe7\xe1\x51\xc4\x4b\xaa\x92\x9f\x46\xfc\x08\xe2\x6b\x6d\x7d\xb6\x1c\xe0\xdb\x96\x9b\x91\x11\x3f\x06\x90\xdc\xde\x9a\xaf\xa6\x11\xef\x44\x5c\xac\xdb\x07\xaa\xbe\x9f\xa4\xad\xb5\x8a\x21\x7e\xe7\x84\x36\xf3\x2c\xe2\x2d\x88\xcf\x37\xe7\xca\x53\xc1\xd8\xab\xaa\x93\xc5\x9c\xc1\x76\xc4\xb7\x35\x5a\xf2\xf2\x3b\x04\xc0\x89\xba\x5b\x9a\xf1\xc7\x72\xfb\x6d\x53\x9e\xf2\xe7\xe5\xea\x35\x9a\xcb\x7b\x79\x44\xfc\xfa\xbc\x79\x48\xf6\x7d\x72\x9f\x39\xed\xe9\x7c\xe8\xf3\x84\x96\x2d\x07\xfc\xa5\x97\x94\x83\x15\xc4\x83\x88\x7f\xa1\x46\x41\xf6\xe7\xe2\xfe\xb1\xdc\x4a\x10\x03\x19\x6a\x1a\xe5\x49\x7f\x2e\x92\xa3\x0e\x5b\x2f\xe3\x5d\x6d\xa6\xe8\xcf\x4b\x0c\x17\x37\xd4\x1f\x4b\x16\xeb\xfa\x81\x52\xa0\xff\x44\xb3\x8b\x72\x80\xaf\x59\x06\xab\x51\x8c\x8d\x4f\xda\x2d\xb9\xea\xeb\xf9\x51\xc5\xce\x17\x7c\x9f\x7c\x85\x36\xab\x81\x7f\xbe\x0d\xb3\x09\x05\x28\x98\x30\x87\xbd\x0a\x4d\x58\x03\x11\x64\x28\x40\x06\xdf\x16\xd8\x68\xa9\x83\x0e\x06\x32\x14\xad\x14\x19\x8a\x5f\xa1\x66\x17\x1b\xe7\xc0\x3c\xf2\x3a\xb4\x99\xcd\xc1\xbe\xc2\x94\xfe\xc8\xc8\x5f\x83\xf9\xb8\xce\xb4\x2a\x64\x87\x3e\x82\x16\xb2\x1a\xfc\x8e\xac\x16\xd3\x65\xf1\xab\x85\x5c\x63\x13\x3f\x7e\x2c\x37\x02\x3f\x26\x19\x20\x12\xd9\x83\xcf\x5e\x32\x49\xf6\x91\x71\x32\x01\x22\x79\x8a\x3c\x4d\xf6\x93\x1c\xb2\x13\x64\xef\xfa\xd8\x4a\x6c\x45\x5e\x3c\x37\xd6\xfd\xbc\x8c\x33\x52\xa6\x9b\x45\xdd\x39\xb4\xbb\xa0\x60\xff\x33\x2a\x4c\x5c\x53\xd7\xac\x2c\x0e\xb6\x86\x23\xcb\x29\xfb\x05\x5d\xbd\xfc\xc6\x5f\xb1\x5c\xe9\x2c\x37\x51\xb6\xe2\x19\x9d\xba\x57\xce\xf9\x5f\xf9\xeb\xfc\x32\xf6\x2b\xfc\x6a\xa4\xe0\x7f\xe4\x57\xf1\xb7\x72\xc7\x5a\xcc\xbb\xb2\x4c\xc3\xec\x6c\x58\x73\x77\x55\x1a\x6d\x06\xe3\x16\xf0\xd1\x99\xc5\x89\xc5\x1d\xf3\x71\xf1\xe4\x57\x0f\x46\x7e\x96\xc9\x99\xe7\xaf\xf4\x5d\x3c\x59\x6f\x2e\x0e\x46\xac\x97\x05\xfa\x6a\xf9\x56\x19\x4e\x8d\x44\xac\xf4\x83\xf4\x87\xb4\x2c\xbd\x27\x7d\x28\xfd\xc6\xbd\xcd\x7d\xca\x7d\xcd\x7d\xce\x7d\xc1\x5d\x02\x91\x3b\xcb\x9d\xe3\xbe\xe1\x2e\x70\x1f\x73\x5f\xc6\xf6\x6a\xf3\x1a\x5a\xdf\x7b\x16\x79\x18\xb7\x67\xe9\x96\x6b\xac\x4a\x21\x23\x6c\x15\x1e\x16\x72\xc2\x36\xe1\x51\x61\x32\xf2\x27\x0c\x08\x63\x42\x51\xd8\x81\x96\xad\xeb\xfb\x16\x9f\x2f\x9e\x3d\x1d\x0e\x63\x1f\xe6\xa7\xfb\x5c\xbe\x2e\x56\x01\x89\xfb\xb1\x02\xf4\x4d\xfe\x55\x55\x54\xe9\x70\x94\x29\x1d\x56\x6f\x4d\x38\xbe\x41\x13\x8c\x24\x43\x64\x8c\x94\x36\x54\xf7\xb8\x57\xf3\xa1\x22\x95\x4f\xe5\x52\x69\x10\x53\x3b\x53\x13\xa9\xb1\xd4\x41\x0f\x87\xb3\xa6\x76\xa0\x6d\x02\xfb\xfc\x1d\xd5\xa9\x6e\xb2\x52\xea\xd2\x63\xde\x7d\x02\x59\xd3\x3a\x6e\xeb\x0d\xcd\x15\x77\x4b\xd2\x93\x62\x1a\xaf\x36\x2a\x96\x9a\xea\xe8\x88\xa8\x18\x86\xc8\x4c\x8e\x68\x53\x87\xda\x6d\x5a\x1b\x05\xef\xde\xf4\x8f\xf4\x9b\x32\xbb\x0f\x13\x5b\x2e\x47\x9c\xfb\x0c\xc0\xbe\x3f\xf1\xec\xfb\x2e\xe2\x8e\xb4\x00\x96\x1c\x80\x81\xc7\x23\x6e\x18\xcf\xca\x07\xde\x05\x38\xf3\x84\xda\xb2\xdb\xc1\x1d\x91\x48\x7c\x0b\xe0\xd4\xf7\xec\xf6\xbf\xfa\x32\x78\x7e\xfd\xd4\xe9\xdc\xc4\x73\xac\xe7\x2d\x80\xb5\x37\x3b\x9d\xbf\xdf\xef\x74\xd6\x3e\x40\xff\xab\x00\x67\x8d\x7f\x01\xa0\x9f\x7c\x55\x03\x5c\x0b\xef\x00\x00\x04\xe6\x49\x44\x41\x54\x58\x09\xc5\x58\xcd\x6f\x1b\x55\x10\xff\xed\xdb\x5d\xaf\x37\x09\x89\x93\x4d\x82\xb1\x49\xdd\xa4\x82\xb4\x4d\x8a\x8a\x88\x48\x69\x4f\x70\x81\x03\xa2\x52\x25\xca\x11\x09\x15\x90\xf8\x17\x90\x10\x37\x4e\x1c\x10\x1c\x2b\x21\x21\x4e\x9c\x43\xab\x8a\x88\x54\x70\x0a\xa2\x12\x0d\x6d\x53\x27\xe4\xab\x49\xed\x7c\xd9\xf1\x66\xe3\xcf\xfd\x62\xde\x3a\x35\x76\xd6\x0e\x29\x22\xf6\x28\x9b\xbc\xaf\x79\xf3\x9b\x99\x37\xef\xcd\x44\x00\xe0\xd2\xd7\x90\x3a\xfa\xdb\x30\xf0\x6e\x0c\x52\xbb\x08\xd7\x71\x61\xa6\x8a\xd0\xe3\x7b\xd8\x9d\xdd\x45\x6e\x27\x57\x97\xaf\xf3\x44\x27\x7a\xc7\xba\x11\x1c\x68\x87\x24\x33\x18\x7f\x19\x58\xfe\x71\x15\xae\xe9\xd4\x5d\xcf\x07\xa5\x86\x33\xfb\x13\xa5\xac\x09\xa5\x4b\x84\x36\xa6\xc1\x2e\x3a\x10\x04\x20\xf2\xa6\x80\x7c\xba\x80\xc7\x13\xeb\x48\x4e\x25\x61\xe5\x4d\x6f\xb5\x40\x42\x87\xde\x89\x21\x72\x39\x0c\x51\x91\x01\x02\x2e\x05\x45\x14\x75\xf3\x50\x10\x9c\x59\xa4\xef\x73\xde\x68\x44\x36\x69\x91\xb9\x6b\x40\xee\x55\x10\x0c\x07\x68\x43\x17\xae\xed\x40\x54\x45\xd2\x5a\x83\xfa\xac\x0a\x67\xc3\x42\x57\x6f\x1b\xa2\x6f\x47\x10\xbd\x32\x00\xd7\x22\xcd\xe9\x13\x44\x86\xdd\xa5\x2c\x16\xae\x2f\xc0\xdc\x07\xdb\x48\x0e\xe9\x77\xb8\x6b\xd4\xf6\x00\x94\x90\x8a\xd0\x89\x0e\x0c\xbe\x17\x83\xd3\xcd\x60\xb9\x65\x13\x73\xeb\x70\x2b\xa8\x0e\x83\xa6\xaa\x58\xcb\x1b\x04\x92\x80\xba\x02\x04\x06\x64\x17\xb3\x88\x7f\x3d\x8f\x5c\xaa\xbe\x0b\xab\x41\xd5\x05\x22\xab\x32\x22\xa3\x7d\x78\xf1\x8d\x28\xa2\x2f\x69\x68\xeb\x57\x11\xa4\x33\x62\x92\x75\x0a\xd9\x12\x76\xf4\x02\x0c\xd7\x44\x89\x5c\xc5\x1c\xb2\x8e\x22\x42\x21\xe1\x79\x38\x1e\x08\x59\x10\xd1\xdd\x19\x84\x90\xb2\x90\xf8\x63\x0b\x8c\x10\x67\x92\x79\x6c\xc6\x77\x90\x5a\xd4\x91\x37\x0a\xd5\x18\xbc\xb6\x0f\xc8\xc9\xf1\x28\x2e\x7c\x30\x8c\xc8\xcb\x1a\x48\x2f\x38\x24\xdc\x21\x57\x38\x24\x88\x13\xdf\x94\xb7\x6c\xd7\xa2\x39\xc1\x03\x02\x45\x20\x1f\x4b\x64\x29\x9b\x80\xd0\x1a\x46\x7d\x32\x09\xe1\x01\x0b\x90\x69\x88\xb8\xf5\x1c\xcb\x45\x6a\x59\xc7\xcc\x0f\x8b\xb8\x7f\x73\x05\xa5\x2a\x77\x55\x80\x48\xb2\x84\xf1\xf7\xcf\x60\xfc\xda\x69\xda\xcc\x85\x55\x6a\x1c\x4c\x0e\x07\xe1\xb9\xc7\x25\x01\x82\xb7\x9e\xf3\x08\x24\x9c\x79\x5f\xe3\x18\x90\x24\x02\x49\x16\x5c\xf8\x39\x81\xc9\x2f\xee\xc0\xd8\x8f\x3c\x0f\x08\xdf\xec\xd2\x87\xe7\x70\xe1\xe3\x33\x30\x29\x4a\x9e\x68\xef\xa9\x72\x4c\xbf\x14\x72\xf5\xe2\xed\x04\x26\x3e\x9b\x46\x29\x67\xc2\xb3\xdb\xe0\x6b\x11\xbc\x7a\x6d\xb8\x69\x20\xb8\x6e\xc5\xac\x8d\xa1\xd7\xa3\x18\x79\x2b\xe6\xa9\xca\x82\x6d\x12\x2e\x7e\x74\x96\xc2\xcd\x6d\x8a\x25\xaa\x0d\x6c\x17\x6c\x9c\xbf\x7a\x0a\xea\x33\x41\xb0\xf0\xd9\x3e\xf4\x9f\x0e\x51\x44\x34\x3e\x13\xd5\xcc\xff\x67\xdb\x22\xe5\x7b\x62\x9d\xd0\x86\xba\xc0\x62\xe3\xfd\x74\xba\xf9\x51\x69\x0d\x09\xb2\x80\xf0\xa8\x06\xd6\xfb\x42\xc8\x0b\xab\xd6\xc0\xa0\x90\xa6\xbb\x51\x3b\xd5\x09\xd6\xa1\x29\xe5\x5e\xab\x90\xd0\x53\xd0\xfd\x9c\x4a\x51\x43\x71\xdd\x4a\xe2\xf7\x8f\x12\x0a\x80\xd1\x35\xd4\x4a\x1c\x15\xd9\xcc\x29\xf0\x27\xbc\x99\x60\xea\x47\x27\xdb\x4b\x13\x10\x7a\x1b\x5a\x49\x36\x87\x90\x59\x31\xc0\x9a\x7a\x4e\x0e\x28\x4d\x46\x28\x64\x4a\x60\x5b\x73\x7a\x2b\x8d\x01\x46\xc9\x53\x26\x91\x05\xdb\x9c\xcb\x50\xaa\x57\x6a\x0a\x18\x9b\x72\x98\x83\xc4\x13\xa8\xed\x78\x9a\x5c\x93\x34\x90\x5a\xc8\x41\xa4\x1b\xee\xb8\x49\x14\xfc\xe9\x81\x5d\xb4\xb1\xfe\x20\x03\xc6\x73\xc9\xf9\xa9\x55\x48\xfb\x09\xcc\xf1\x82\xa9\x55\x96\x2b\xbf\x11\xcf\x20\xfd\x48\x2f\xa7\x01\xf3\x94\xa4\xe4\x76\x29\x27\xe0\x69\x54\x13\x49\x0a\x88\x88\xdf\x5a\xf5\x32\x35\x2f\x1f\x49\xaf\xe9\x88\xdf\x78\x04\x49\x2d\xa7\x75\xb5\x58\xea\xc7\x7d\xed\x9a\x43\x7a\x94\x66\xd6\xcb\xcf\x19\x79\x49\x4f\xe4\x30\x37\x99\xf0\x98\x2b\x92\xef\x7c\x3f\x07\x63\xa3\x08\x91\x17\x18\x35\xc4\xad\x44\x85\x95\x6d\xd5\x8c\x1e\xb9\xe3\xbd\xec\x7e\x4b\x4b\x8a\x84\xe9\xeb\x0f\x61\xa4\xf6\xbc\xad\x2a\x40\x32\xeb\x06\x7e\xf9\x6a\x06\x22\x99\xcb\x4f\x02\x64\xd1\x7f\xd0\xfc\xeb\xea\x8d\xf8\x41\x04\xda\x28\x67\x9d\x7c\x8c\xfb\xb7\x96\x2a\x0c\x15\x20\x7c\xe4\xe1\x4f\x4b\x98\xfe\x76\x0e\x4a\xc7\x7f\x15\x5a\xd9\xb7\x61\x43\x26\xf7\x6f\xc6\x75\x4c\x7d\x79\x97\x2a\xc7\x7f\xac\xec\xab\xf4\x1e\xff\xb9\x8d\x60\x40\xc2\xf3\x63\x7d\x54\xb1\x51\xb1\xd4\x70\x4b\x3e\x71\x70\xd6\xaf\x7d\x35\x3b\xb7\xc4\x16\x85\xea\x8d\x4f\xa7\xb1\x93\xdc\xad\x9e\xf2\x5e\xbb\x83\xbb\xd1\x95\xcf\xf0\xca\xd5\x61\x5c\xa4\xac\x9e\x51\xed\x6a\x15\xca\x95\x5d\x0d\xe7\x53\x74\x64\x0a\x53\x81\xf6\x9c\x9d\x58\xc1\xaf\xdf\xdc\x83\x91\xce\xfa\xb8\xcb\x27\xd1\x37\x5c\x1e\x88\x9e\xeb\xc7\xa5\x4f\x46\x30\xc0\xad\x43\xa5\xa4\x5d\xb2\x8f\x9c\x60\xf3\xab\x40\x0c\x10\x00\x3a\xac\x1b\xf7\x74\xfc\xfe\xdd\x03\xc4\x6f\xaf\x79\x35\x50\x3d\x71\x87\x02\xe1\x0c\xbc\xfc\x8c\x8d\x85\x31\x7a\xf9\x24\xa2\xe7\x7b\x11\x0c\xf1\x2a\x9f\x7e\x78\x58\x52\xb5\xcf\x2b\x3b\x8f\xe8\xf1\xe2\x15\xde\x93\xfc\x37\x9f\x2e\x22\x39\xb3\x8d\xd9\x9b\x6b\x58\xf9\x2d\x89\xfc\xde\xe1\xcf\xc8\xbf\x02\xd9\x17\xe3\xfd\xd1\x06\x42\x94\xe8\xf6\x20\x3c\xd2\x8d\x9e\xc1\x0e\xb4\xf7\xa8\x08\xd0\x7f\x05\x4a\x74\x96\x4c\xaa\x67\x8d\xf5\x02\xd2\xcb\x7b\x74\x65\xef\x60\x7b\x3e\x03\x1e\x89\x47\xa5\xa7\x02\x52\xbd\xa9\x40\xda\x07\x55\xc5\xab\x6d\xb9\xdb\x8a\x45\xb3\x26\x0a\xaa\xd7\x1e\xa5\xfd\x37\xaa\x63\xe7\x4e\xad\x72\x60\x70\x00\x00\x00\x00\x49\x45\x4e\x44\xae\x42\x60\x82\x03\x00\x51\x21\x41\xd4\x44\x09\x00\x00")
 
 func green_png() ([]byte, error) {
 	return bindata_read(
@@ -34,7 +34,16 @@ func green_png() ([]byte, error) {
 	)
 }
 
-var _red_png = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x26\x09\xd9\xf6\x89\x50\x4e\x47\x0d\x0a\x1a\x0a\x00\x00\x00\x0d\x49\x48\x44\x52\x00\x00\x00\x22\x00\x00\x00\x22\x08\x06\x00\x00\x00\x3a\x47\x0b\xc2\x00\x00\x04\x19\x69\x43\x43\x50\x6b\x43\x47\x43\x6f\x6c\x6f\x72\x53\x70\x61\x63\x65\x47\x65\x6e\x65\x72\x69\x63\x52\x47\x42\x00\x00\x38\x8d\x8d\x55\x5d\x68\x1c\x55\x14\x3e\xbb\x73\x67\x23\x24\xce\x53\x6c\x34\x85\x74\xa8\x3f\x0d\x25\x0d\x93\x56\x34\xa1\xb4\xba\x7f\xdd\xdd\x36\x6e\x96\x49\x36\xda\x22\xe8\x64\xf6\xee\xce\x98\xc9\xce\x38\x33\xbb\xfd\xa1\x4f\x45\x50\x7c\x31\xea\x9b\x14\xc4\xbf\xb7\x80\x20\x28\xf5\x0f\xdb\x3e\xb4\x2f\x95\x0a\x25\xda\xd4\x20\x28\x3e\xb4\xf8\x83\x50\xe8\x8b\xa6\xeb\x99\x3b\x33\x99\x69\xba\xb1\xde\x65\xee\x7c\xf3\x9d\xef\x9e\x7b\xee\xb9\x67\xef\x05\xe8\xb9\xaa\x58\x96\x91\x14\x01\x16\x9a\xae\x2d\x17\x32\xe2\x73\x87\x8f\x88\x3d\x2b\x90\x84\x87\xa0\x17\x06\xa1\x57\x51\x1d\x2b\x5d\xa9\x4c\x02\x36\x4f\x0b\x77\xb5\x5b\xdf\x43\xc2\x7b\x5f\xd9\xd5\xdd\xfe\x9f\xad\xb7\x46\x1d\x15\x20\x71\x1f\x62\xb3\xe6\xa8\x0b\x88\x8f\x01\xf0\xa7\x55\xcb\x76\x01\x7a\xfa\x91\x1f\x3f\xea\x5a\x1e\xf6\x62\xe8\xb7\x31\x40\xc4\x2f\x7a\xb8\xe1\x63\xd7\xc3\x73\x3e\x7e\x8d\x69\x66\xe4\x2c\xe2\xd3\x88\x05\x55\x53\x6a\x88\x97\x10\x8f\xcc\xc5\xf8\x46\x0c\xfb\x31\xb0\xd6\x5f\xa0\x4d\x6a\xeb\xaa\xe8\xe5\xa2\x62\x9b\x75\xdd\xa0\xb1\x70\xef\x61\xfe\x9f\x6d\xc1\x68\x85\xf3\x6d\xc3\xa7\xcf\x99\x9f\x3e\x84\xef\x61\x5c\xfb\x2b\x35\x25\xe7\xe1\x51\xc4\x4b\xaa\x92\x9f\x46\xfc\x08\xe2\x6b\x6d\x7d\xb6\x1c\xe0\xdb\x96\x9b\x91\x11\x3f\x06\x90\xdc\xde\x9a\xaf\xa6\x11\xef\x44\x5c\xac\xdb\x07\xaa\xbe\x9f\xa4\xad\xb5\x8a\x21\x7e\xe7\x84\x36\xf3\x2c\xe2\x2d\x88\xcf\x37\xe7\xca\x53\xc1\xd8\xab\xaa\x93\xc5\x9c\xc1\x76\xc4\xb7\x35\x5a\xf2\xf2\x3b\x04\xc0\x89\xba\x5b\x9a\xf1\xc7\x72\xfb\x6d\x53\x9e\xf2\xe7\xe5\xea\x35\x9a\xcb\x7b\x79\x44\xfc\xfa\xbc\x79\x48\xf6\x7d\x72\x9f\x39\xed\xe9\x7c\xe8\xf3\x84\x96\x2d\x07\xfc\xa5\x97\x94\x83\x15\xc4\x83\x88\x7f\xa1\x46\x41\xf6\xe7\xe2\xfe\xb1\xdc\x4a\x10\x03\x19\x6a\x1a\xe5\x49\x7f\x2e\x92\xa3\x0e\x5b\x2f\xe3\x5d\x6d\xa6\xe8\xcf\x4b\x0c\x17\x37\xd4\x1f\x4b\x16\xeb\xfa\x81\x52\xa0\xff\x44\xb3\x8b\x72\x80\xaf\x59\x06\xab\x51\x8c\x8d\x4f\xda\x2d\xb9\xea\xeb\xf9\x51\xc5\xce\x17\x7c\x9f\x7c\x85\x36\xab\x81\x7f\xbe\x0d\xb3\x09\x05\x28\x98\x30\x87\xbd\x0a\x4d\x58\x03\x11\x64\x28\x40\x06\xdf\x16\xd8\x68\xa9\x83\x0e\x06\x32\x14\xad\x14\x19\x8a\x5f\xa1\x66\x17\x1b\xe7\xc0\x3c\xf2\x3a\xb4\x99\xcd\xc1\xbe\xc2\x94\xfe\xc8\xc8\x5f\x83\xf9\xb8\xce\xb4\x2a\x64\x87\x3e\x82\x16\xb2\x1a\xfc\x8e\xac\x16\xd3\x65\xf1\xab\x85\x5c\x63\x13\x3f\x7e\x2c\x37\x02\x3f\x26\x19\x20\x12\xd9\x83\xcf\x5e\x32\x49\xf6\x91\x71\x32\x01\x22\x79\x8a\x3c\x4d\xf6\x93\x1c\xb2\x13\x64\xef\xfa\xd8\x4a\x6c\x45\x5e\x3c\x37\xd6\xfd\xbc\x8c\x33\x52\xa6\x9b\x45\xdd\x39\xb4\xbb\xa0\x60\xff\x33\x2a\x4c\x5c\x53\xd7\xac\x2c\x0e\xb6\x86\x23\xcb\x29\xfb\x05\x5d\xbd\xfc\xc6\x5f\xb1\x5c\xe9\x2c\x37\x51\xb6\xe2\x19\x9d\xba\x57\xce\xf9\x5f\xf9\xeb\xfc\x32\xf6\x2b\xfc\x6a\xa4\xe0\x7f\xe4\x57\xf1\xb7\x72\xc7\x5a\xcc\xbb\xb2\x4c\xc3\xec\x6c\x58\x73\x77\x55\x1a\x6d\x06\xe3\x16\xf0\xd1\x99\xc5\x89\xc5\x1d\xf3\x71\xf1\xe4\x57\x0f\x46\x7e\x96\xc9\x99\xe7\xaf\xf4\x5d\x3c\x59\x6f\x2e\x0e\x46\xac\x97\x05\xfa\x6a\xf9\x56\x19\x4e\x8d\x44\xac\xf4\x83\xf4\x87\xb4\x2c\xbd\x27\x7d\x28\xfd\xc6\xbd\xcd\x7d\xca\x7d\xcd\x7d\xce\x7d\xc1\x5d\x02\x91\x3b\xcb\x9d\xe3\xbe\xe1\x2e\x70\x1f\x73\x5f\xc6\xf6\x6a\xf3\x1a\x5a\xdf\x7b\x16\x79\x18\xb7\x67\xe9\x96\x6b\xac\x4a\x21\x23\x6c\x15\x1e\x16\x72\xc2\x36\xe1\x51\x61\x32\xf2\x27\x0c\x08\x63\x42\x51\xd8\x81\x96\xad\xeb\xfb\x16\x9f\x2f\x9e\x3d\x1d\x0e\x63\x1f\xe6\xa7\xfb\x5c\xbe\x2e\x56\x01\x89\xfb\xb1\x02\xf4\x4d\xfe\x55\x55\x54\xe9\x70\x94\x29\x1d\x56\x6f\x4d\x38\xbe\x41\x13\x8c\x24\x43\x64\x8c\x94\x36\x54\xf7\xb8\x57\xf3\xa1\x22\x95\x4f\xe5\x52\x69\x10\x53\x3b\x53\x13\xa9\xb1\xd4\x41\x0f\x87\xb3\xa6\x76\xa0\x6d\x02\xfb\xfc\x1d\xd5\xa9\x6e\xb2\x52\xea\xd2\x63\xde\x7d\x02\x59\xd3\x3a\x6e\xeb\x0d\xcd\x15\x77\x4b\xd2\x93\x62\x1a\xaf\x36\x2a\x96\x9a\xea\xe8\x88\xa8\x18\x86\xc8\x4c\x8e\x68\x53\x87\xda\x6d\x5a\x1b\x05\xef\xde\xf4\x8f\xf4\x9b\x32\xbb\x0f\x13\x5b\x2e\x47\x9c\xfb\x0c\xc0\xbe\x3f\xf1\xec\xfb\x2e\xe2\x8e\xb4\x00\x96\x1c\x80\x81\xc7\x23\x6e\x18\xcf\xca\x07\xde\x05\x38\xf3\x84\xda\xb2\xdb\xc1\x1d\x91\x48\x7c\x0b\xe0\xd4\xf7\xec\xf6\xbf\xfa\x32\x78\x7e\xfd\xd4\xe9\xdc\xc4\x73\xac\xe7\x2d\x80\xb5\x37\x3b\x9d\xbf\xdf\xef\x74\xd6\x3e\x40\xff\xab\x00\x67\x8d\x7f\x01\xa0\x9f\x7c\x55\x03\x5c\x0b\xef\x00\x00\x04\xc8\x49\x44\x41\x54\x58\x09\xbd\x58\x5b\x68\x1c\x55\x18\xfe\xce\x99\x99\xbd\x6f\x36\xdb\xe8\x12\x1b\xd3\x50\x14\x8a\x78\x01\xa1\x54\xe3\x0d\x24\x8a\xf4\xc1\x1b\x1a\xdf\x44\xf0\xc1\x67\x7d\x2a\x28\x48\x05\xa5\x3e\x8a\x2f\x22\xf8\x22\x45\xaa\x4f\xbe\x28\x82\x04\x7c\xf0\xc9\x1b\x56\x9b\xa6\xd4\x76\x49\x24\xb4\x9a\x8b\xd9\xec\x6d\x76\x76\x67\x66\xc7\xef\xcc\x6e\x36\x7b\x99\xdd\x34\xcd\x6e\x7f\x98\xdb\xb9\xfc\xff\xf7\x5f\xce\x7f\xfe\x33\x02\x80\xc7\xab\x2f\x25\x32\x31\x4c\xcf\xcf\x40\x8f\x6b\xf0\xea\x1e\xec\xff\xaa\xc8\x5f\x2e\xa1\x70\xa9\x00\x33\x67\x06\xce\x1b\x3b\x32\x86\xdb\x8e\xa7\x11\x99\x8e\x43\x37\x24\x8a\x57\x8b\x58\xf9\x76\x15\x9e\x5d\x0f\x1c\xaf\x1a\xf5\xbe\x3d\xcd\x8e\x5a\xd9\x46\x38\xa5\x61\xe2\xf8\x04\xdc\x6a\x1d\x42\x00\x87\x9f\x11\xa8\x6c\x59\xb8\xf6\xcd\xbf\xf8\xe7\x87\xeb\x70\x2a\x8e\x3f\x5a\x0f\x49\xcc\x3c\x3b\x83\xc3\xcf\x4f\x42\x0b\x1b\x00\x81\xeb\x11\x0d\xd5\xbc\x3d\x10\x84\x9a\xac\xf1\x3a\xad\x5e\xfa\x91\x4b\x2d\xb6\x2f\x14\xa1\x4f\x46\x79\x85\x00\xa5\x95\x53\x87\x16\xd5\xa8\xf5\x04\x22\x93\x31\x24\xfe\xb2\x70\xa7\x66\xe0\xd0\x4b\xd3\x98\x7a\x79\x1a\x75\xf6\xab\x31\x9e\x46\x6b\x2c\x97\x91\xfd\x2c\x0b\xbb\x62\xf7\x13\xe1\xb7\x53\xbf\x3d\x5c\xa3\xeb\xb8\xdd\x30\x70\x24\x19\xc2\xb1\xe7\xa6\xb0\x32\x3b\x8e\x7c\x54\x42\x72\x9a\x50\x96\x0e\x6b\x78\xe0\xa2\x89\xb9\x4b\x55\x7c\xf4\x62\x1a\x75\xd7\xf5\x19\x6a\x52\xc2\xa6\xfb\x16\x3f\xcd\xc2\x5c\x2b\x0d\x04\xa1\x3a\x03\x81\xc4\x74\x81\x87\x13\x49\xcc\xc7\xe3\x78\x22\x62\xe0\xa8\x34\x60\x68\x02\x82\x4a\x2d\x67\x74\x2c\x1c\x0b\x61\x71\x3a\x84\xad\x31\xc6\x8d\xf4\x90\x2a\x78\x98\xca\x39\xc8\xb2\xcf\xe1\xdc\x4c\xde\xc5\xe3\x57\x6b\xb8\xe3\x7c\x01\x3f\xe7\x4d\xb8\xba\xc4\x8a\x6d\xe3\xd7\xaa\x8d\x8b\x96\x85\x7c\xad\xd7\x3a\x3d\x40\x9e\x4e\x25\x71\x7a\x3c\x8d\x13\xb1\x48\x43\x0b\x4f\xc5\xf2\x6e\x3c\x0b\x97\xef\x0e\xa7\xe9\x1e\x5c\x9a\x1e\x04\xa2\xd9\xcd\x7e\x06\x90\xc7\x26\x7f\x4c\x9d\xef\x0c\x13\x08\xd5\xd0\x60\x05\xf2\xba\x62\x55\xf1\x71\xa9\x8c\xb3\xb9\x1c\xca\xca\x85\x4d\x6a\x01\x09\x49\x81\xb7\x33\x19\xbc\x93\x1e\x6b\x74\xf9\x00\x76\x86\x75\x3e\x45\x8d\xc1\xa9\x98\x48\x86\x98\xd6\x04\xa1\x1e\xaa\x8d\xda\x83\xee\x54\x80\x82\x89\x22\x29\xeb\xbb\x62\x19\xaf\x6f\x6c\x60\xd3\xaa\xf9\xc3\x5a\x40\xde\x9f\xcc\xe0\xd4\xa1\x71\x46\xfa\x2e\xca\x60\x46\x43\x6a\x65\x0c\x2d\x14\x4d\xcc\x5f\xbf\x86\x12\xad\xec\xe3\x3e\x39\x9e\xc2\xa9\xf4\x2d\x04\xa1\x74\xa1\xc2\x4f\x25\x63\x78\x55\x29\x4f\x92\x09\x9a\xf1\x8c\xfa\x18\xe0\x0a\x7f\xe4\x28\x6e\xcc\x33\x6f\x25\x93\x48\x85\x0c\xc8\x13\xf1\x18\xee\x0d\x87\x29\xa6\xe9\xeb\x51\x08\xec\xcb\xd3\xc3\x51\xca\xbe\x2f\x12\x81\x3c\x19\x8b\xee\x46\x75\xd7\x04\x61\xb9\x5d\x2d\x37\xff\xd9\x97\x17\x57\xda\xa3\x51\x02\x79\x30\xc4\x2c\x4f\x13\x05\x91\xc7\xf4\x3c\x2c\x52\xbc\x44\x90\x1c\x8a\xbe\x9f\x09\x53\x4e\x89\x3d\xb7\x9b\x61\x61\x01\xd4\xb2\xef\x26\xc6\xe6\x5d\xba\x06\x29\xb9\xa6\x6f\x19\x05\x59\x84\xc2\x27\x98\x8f\x64\xa0\xb9\x46\x85\x2c\xd2\xdf\xfa\xb2\x2c\x7a\xe3\x43\x8c\x28\xa9\x79\x5c\x18\xfd\x78\xcb\x35\xee\x96\xad\xbd\x60\x54\x96\x68\xf2\xe5\xb6\x19\x28\xc1\x81\x0b\xb9\xe4\x30\x80\x54\xb5\xd3\x41\xdd\xdf\x1d\x9d\x37\xfd\xe1\x55\xab\x9c\xdb\xcb\x7b\xdd\x15\x90\xbf\x57\x1b\x9b\x4e\x3b\x77\x6f\x44\x01\x2c\x2a\x55\x6e\x86\x5d\x40\x68\x84\xac\x53\x83\xfc\x8d\x28\x3d\x3b\x60\x59\xb5\x23\x1b\xe5\x3b\x71\xfd\x42\x63\xc8\x15\xab\x82\x25\x15\x27\x01\x26\x1b\xba\x7c\x23\x60\xd5\x70\x49\xff\xa4\x80\x98\x8e\x87\xaf\xca\x2c\xe5\xba\x4d\xa6\xa0\x35\x6b\x85\x61\x00\x52\xbc\x54\x0d\xdb\x49\x02\x8b\xf4\xc8\x95\x8a\xd9\x28\x03\xbe\x64\x5d\x60\xb9\xbd\xee\xf1\x58\x26\x0e\x8b\x02\x79\x51\xf9\xcf\xcb\x65\xbf\x52\xf3\x21\x2e\x9b\x15\x9c\x65\xc5\xc4\x3c\xdb\x25\xb7\x2b\xb0\xba\x7a\xf7\xf7\xd9\xcb\x6b\x83\x35\xec\x39\x25\x97\xd4\x92\x7c\x66\xbb\x80\x1c\x3b\x82\x62\x45\xb8\x23\xa8\xda\xa8\xf4\xbb\xf9\x02\xd6\x2a\x56\x27\x90\x55\x5a\xe5\xcd\xed\xed\xc0\x58\xe9\xf5\xad\x3f\x77\xe0\x4d\x98\xb5\xe0\xdd\x56\xcd\x62\x41\xad\x6a\xd6\x2f\xb6\x72\x2d\x1e\x1d\x07\xac\x0b\x44\x17\xe7\x06\xf4\x48\x9c\x35\xca\x41\x2b\x36\x83\xac\x7b\x12\x65\x03\xc4\x65\xcb\xc6\x2b\x2c\x9c\x73\x6d\xc7\x8a\x96\x6b\x76\xa0\xbd\xb7\xb1\x89\x4f\xe8\xa6\xde\x78\xd9\x19\x71\x80\x27\xdd\xf1\x07\xcf\x35\x2f\xac\xf3\xa8\x4a\x0f\xb4\x53\x87\x45\x54\x87\x43\x4b\x2c\xf0\xdc\x51\xa0\x36\x4f\xf2\x6c\x23\x83\xb4\x6a\xe7\x70\x43\xef\x0c\x54\xba\xe3\x5c\xb1\x84\xd7\x36\xd6\xb1\x6a\xaa\x54\xdf\x49\x2a\x94\x7b\xb7\xdf\xe6\x98\x87\xc6\x12\xf8\x30\x3d\x81\xc7\xe2\xac\x69\x95\xab\xf6\xeb\x2e\xa5\x04\xaf\x3f\x99\x43\x3e\xe0\x81\xea\x6b\x5a\xda\xeb\xc3\x63\x20\x10\x85\x47\x1d\x3f\xe7\x92\x29\xbc\x91\x8a\x63\xce\x88\xc2\xe0\x6f\x06\x1f\x7a\x8b\xa1\xd2\x43\xb1\x69\x3e\x94\x70\x36\x95\x98\xad\x7f\x64\xcc\xa9\x3c\xf1\x3d\x2d\x51\xdc\x63\x1b\xd9\x13\x48\x43\x42\xe3\x7e\x37\x83\x78\x96\x15\xf7\x6c\x38\x8a\x7b\x0c\x81\x29\x4d\x43\x92\xa5\x66\x0d\x75\x6c\x7a\x75\xfc\x4d\x61\x4b\xbc\x54\xca\x3e\xcf\x8c\xa9\x56\xe2\x8d\xd2\xbe\x80\xb4\x33\x15\xd4\x3c\xc1\x5a\x33\xc4\xb4\xcd\x1f\x10\xb0\x6a\x2e\x2a\x07\x28\xa8\xfe\x07\xc2\xd0\xce\x29\xe3\xbd\xb1\xa3\x00\x00\x00\x00\x49\x45\x4e\x44\xae\x42\x60\x82\x01\x00\x00\xff\xff\x49\x4b\xd6\xbe\x26\x09\x00\x00")
+var _orange_png = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x2c\x04\xd3\xfb\x89\x50\x4e\x47\x0d\x0a\x1a\x0a\x00\x00\x00\x0d\x49\x48\x44\x52\x00\x00\x00\x22\x00\x00\x00\x22\x08\x02\x00\x00\x00\xb5\x25\x9c\x95\x00\x00\x03\xf3\x49\x44\x41\x54\x78\x9c\xb4\x56\x4d\x6c\x1b\x45\x14\x9e\x37\xb3\xeb\x5d\xc7\x4e\xec\xd6\x69\x68\x9d\x46\x69\x92\x16\x0a\x01\x09\x90\x28\x84\x16\x24\x14\x21\x5a\xc1\xa1\x07\x40\xdc\x10\x1c\x38\xc3\x11\x90\x10\x1c\x50\x39\x23\x71\x82\x4b\x14\xa1\x82\x38\x20\xc4\xa1\x80\x7a\x40\x42\x48\x4d\x9b\x8a\xb6\x34\xa1\x28\x44\x75\x93\xc6\x26\x76\x52\x27\xb6\xf7\x77\x76\xe7\xa1\x35\xd9\xda\xbb\xf6\x86\x38\x2e\xdf\xe7\xc3\x78\xde\xbc\xf7\xcd\xbc\xf9\x79\x2b\x91\x08\x24\x95\x9e\xa1\x3d\xc3\x12\x65\x88\xc8\x5d\x6b\xd3\xac\x55\x8c\x8a\xce\x75\xdf\xbe\x85\x3e\xb5\xaf\x3f\xb9\x47\x95\x12\x12\xa3\x55\xb3\x9a\x5b\x5f\x46\x22\x7c\x63\x03\x91\x32\xb6\xc3\x15\x89\x65\x12\x19\x57\x08\x00\x92\x25\x60\xd8\xe6\xca\xe6\xdf\x85\xcd\xbc\x23\x1c\x42\x88\x44\xe9\xf0\xde\xe1\x6c\x6a\x3f\xa3\x32\x21\x28\x31\x66\x71\xde\x56\x83\x10\xc2\xfc\x46\x18\x2e\x8a\x0d\xbd\x2a\xb1\xb8\x44\x63\xc4\x73\x16\x8c\xb2\xfe\x64\x46\x95\x7a\x92\x8a\x79\x30\x2d\xef\x4d\x0c\x0d\xa6\x87\x04\x7a\x26\x24\xb4\x6a\x6a\x8b\xa5\x45\x2e\xb8\x1f\x60\x67\x32\x49\x45\xba\xaf\x8f\x65\xd3\xb5\xf1\xac\xac\xd9\xaa\x66\x53\x44\xe2\xa2\x50\xe5\xc4\xf1\xb1\xd4\x1b\x13\x7d\x57\x6f\x0f\x70\xd7\x75\x05\x10\xc2\x6c\xa7\x32\x57\x58\xd0\xb9\xe6\x7b\x87\x01\x7e\x63\x0b\x3d\x32\x3c\x35\xd2\xfb\xca\xe3\x89\x67\x8f\xc8\x23\x19\x59\x66\x00\x40\x6e\xae\x49\xe7\x6f\xc4\xae\xe7\x63\x77\x74\x6f\xab\x52\x71\x1c\x4c\x3b\x8b\x25\xc9\x11\x30\xd0\xeb\x3e\x73\xd8\x3e\x90\xaa\x5c\xcc\xe9\xae\xa0\xb9\x75\x3e\xbb\xc4\xe7\xf2\xe6\xa6\xc9\x23\x65\x9e\x3f\xda\xfb\xe1\x8b\x7b\x8e\x1d\x52\xbd\x3f\x04\xeb\x3f\x8f\x00\x58\x9f\x10\xba\x82\x12\x82\x8c\xfe\xdb\x0f\x88\x5b\x26\xf4\x3a\xa8\x1f\x06\x17\x8a\xd6\xa7\x3f\x6b\xd3\x33\x65\xcd\x16\x01\x99\x18\x83\xf7\x4e\x0e\xbc\x7f\xb2\xcf\x17\x08\x03\xc0\xa9\xef\x10\x6b\xb2\x8a\x7a\x68\x09\xdb\x0d\x27\x04\xce\xcd\x69\x6f\x4e\x97\xd6\x34\xbb\xb1\x37\x1f\xbd\x34\xf0\xee\x0b\xe9\xb6\x02\x3e\x69\x7d\x30\x6d\xfa\xb1\xa6\x15\xb4\x02\x8f\x0c\x28\x8f\x1e\x8c\x7d\x77\xad\x6a\xbb\x9e\x27\x39\xf5\x50\xea\xb3\xd7\xfa\x49\xc4\x59\xec\x02\x38\xda\x1f\xcb\x6f\x88\xd9\x25\x93\x26\x15\xe9\xcc\xe9\xed\xd7\xd1\x0d\xf0\x9d\xc9\xde\x94\x2a\xd3\x63\xc3\x3d\xe3\x07\x94\xff\x4f\x66\x24\xa3\x3c\x9c\x55\xe9\xa9\xf1\xb8\xdf\xd5\x00\x80\xeb\x37\x3b\x43\x3b\x47\x38\x3e\xa6\xd2\xc7\x86\xa4\xd6\xa5\x20\x46\x5e\xdb\xed\x89\xc8\xea\x47\x3c\xc0\x47\xb2\x32\x1d\x4c\x47\x3e\x6b\xbb\xa5\x13\x92\x1e\xdb\xc7\x28\x85\xc0\x0d\xbd\x17\x0c\xaf\x26\x93\x60\xb4\x75\x8d\x5d\xa3\x4d\x7a\xa8\x66\x05\x64\x00\xba\xbd\x3d\x88\xf1\xd6\x20\x74\xb5\xba\xcb\x43\x15\x45\x68\xd9\x05\x47\xb8\x74\xbe\xe0\x04\x1f\xd0\xf0\xa0\x4e\x81\x68\x85\x82\x14\xab\x40\x7f\x5b\xb6\x9b\x83\x20\x76\x2b\x03\x60\x05\x83\xc0\x62\xc9\xa6\x97\x97\x2c\x0c\x1f\xc1\x7b\x8c\x4b\xb7\x6c\x9a\x5b\x37\xe6\x0b\x6e\xf7\xb9\x8a\x3e\x69\x38\x93\xb3\xa9\xce\xf1\xeb\xcb\xb5\x90\x0c\x40\x20\x93\x3b\x27\x80\x8d\xd8\x5c\x1d\xe0\x7a\xde\x5a\x28\xea\x5e\xd7\x57\xb3\xba\xc9\x03\x79\x43\x94\xfd\x66\x67\x68\x71\x84\xa9\x0b\x9a\x66\x7b\x45\x97\xdc\x5c\x37\xa6\x2f\x6a\xc1\x1a\xb5\xeb\x1c\x06\x1c\x4b\x35\x7e\x76\x56\x6b\x14\xf0\x33\x3f\x56\xca\x3a\x0f\x0d\xea\xfa\xaa\xd2\x0f\xbe\xaf\xac\x56\xcd\x86\xcc\x72\xd9\x78\xfb\x9b\x8d\x90\x4c\x30\xcb\x91\x00\xb0\xdb\xbd\x58\xf4\xdc\x9c\xf6\xe5\xa5\x72\xf8\x3b\xed\xf7\xbc\x99\x50\xd8\xd3\xa3\xf1\xd6\xb7\xef\xbf\xc0\x42\xf3\x23\x84\xfe\xb9\xca\x5f\xfd\xa2\x54\x36\x78\x58\x86\x10\xf2\xeb\xa2\x91\x8e\x4b\x4f\x0c\xab\x9d\x2b\x35\x83\x5e\x5d\x31\x5f\xfe\xbc\xb8\x54\x36\xfc\x9e\xa0\x8c\x23\xf0\xfc\x0d\xad\x62\xc1\x73\xf7\xab\xbb\x2a\x10\x40\x08\x3d\x3b\x5b\x7b\x7d\xaa\xb8\xbc\x61\x85\x0c\x6d\xf8\xe4\xa1\xe4\x27\xa7\x33\x27\xc6\x94\xe6\x8f\xc2\x6d\x09\x84\xc0\xb5\x15\xfb\xe3\x1f\xca\xdf\x5e\xa9\x60\x8b\x4b\xe4\x94\x7b\x64\x98\x3c\x9a\x7a\xeb\x44\x62\xf2\x81\xb8\xcc\x68\x53\xbd\xc2\x26\x2f\xcf\xbd\x66\xb9\xbf\xfc\x65\x4e\x5d\xd0\x7e\xfa\xa3\x56\xb5\xda\xbf\x5b\x91\x32\x77\x79\x78\x5f\x7c\x62\x44\x9d\x18\x8d\x3f\xb8\x1f\x06\xd3\xac\x57\x91\x6c\x57\xac\xd5\xc4\xad\x3b\xce\x7c\xc1\x99\xc9\xd9\x57\x6e\x5b\xcb\x65\xa3\xbd\xf3\xce\x65\xee\x02\x08\x24\x15\x16\x93\xa8\x40\x34\xb9\x6b\xf0\x0e\x6e\xd5\x3f\x03\x00\xa2\xb3\xa9\xbe\xa4\xb0\x4a\x7f\x00\x00\x00\x00\x49\x45\x4e\x44\xae\x42\x60\x82\x03\x00\xbe\x20\x13\x3d\x2c\x04\x00\x00")
+
+func orange_png() ([]byte, error) {
+	return bindata_read(
+		_orange_png,
+		"orange.png",
+	)
+}
+
+var _red_png = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x26\x09\xd9\xf6\x89\x50\x4e\x47\x0d\x0a\x1a\x0a\x00\x00\x00\x0d\x49\x48\x44\x52\x00\x00\x00\x22\x00\x00\x00\x22\x08\x06\x00\x00\x00\x3a\x47\x0b\xc2\x00\x00\x04\x19\x69\x43\x43\x50\x6b\x43\x47\x43\x6f\x6c\x6f\x72\x53\x70\x61\x63\x65\x47\x65\x6e\x65\x72\x69\x63\x52\x47\x42\x00\x00\x38\x8d\x8d\x55\x5d\x68\x1c\x55\x14\x3e\xbb\x73\x67\x23\x24\xce\x53\x6c\x34\x85\x74\xa8\x3f\x0d\x25\x0d\x93\x56\x34\xa1\xb4\xba\x7f\xdd\xdd\x36\x6e\x96\x49\x36\xda\x22\xe8\x64\xf6\xee\xce\x98\xc9\xce\x38\x33\xbb\xfd\xa1\x4f\x45\x50\x7c\x31\xea\x9b\x14\xc4\xbf\xb7\x80\x20\x28\xf5\x0f\xdb\x3e\xb4\x2f\x95\x0a\x25\xda\xd4\x20\x28\x3e\xb4\xf8\x83\x50\xe8\x8b\xa6\xeb\x99\x3b\x33\x99\x69\xba\xb1\xde\x65\xee\x7c\xf3\x9d\xef\x9e\x7b\xee\xb9\x67\xef\x05\xe8\xb9\xaa\x58\x96\x91\x14\x01\x16\x9a\xae\x2d\x17\x32\xe2\x73\x87\x8f\x88\x3d\x2b\x90\x84\x87\xa0\x17\x06\xa1\x57\x51\x1d\x2b\x5d\xa9\x4c\x02\x36\x4f\x0b\x77\xb5\x5b\xdf\x43\xc2\x7b\x5f\xd9\xd5\xdd\xfe\x9f\xad\xb7\x46\x1d\x15\x20\x71\x1f\x62\xb3\xe6\xa8\x0b\x88\x8f\x01\xf0\xa7\x55\xcb\x76\x01\x7a\xfa\x91\x1f\x3f\xea\x5a\x1e\xf6\x62\xe8\xb7\x31\x40\xc4\x2f\x7a\xb8\xe1\x63\xd7\xc3\x73\x3e\x7e\x8d\x69\x66\xe4\x2c\xe2\xd3\x88\x05\x55\x53\x6a\x88\x97\x10\x8f\xcc\xc5\xf8\x46\x0c\xfb\x31\xb0\xd6\x5f\xa0\x4d\x6a\xeb\xaa\xe8\xe5\xa2\x62\x9b\x75\xdd\xa0\xb1\x70\xef\x61\xfe\x9f\x6d\xc1\x68\x85\xf3\x6d\xc3\xa7\xcf\x99\x9f\x3e\x84\xef\x61\x5c\xfb\x2b\x35\x25\xe7\xe1\x51\xc4\x4b\xaa\x92\x9f\x46\xfc\x08\xe2\x6b\x6d\x7d\xb6\x1c\xe0\xdb\x96\x9b\x91\x11\x3f\x06\x90\xdc\xde\x9a\xaf\xa6\x11\xef\x44\x5c\xac\xdb\x07\xaa\xbe\x9f\xa4\xad\xb5\x8a\x21\x7e\xe7\x84\x36\xf3\x2c\xe2\x2d\x88\xcf\x37\xe7\xca\x53\xc1\xd8\xab\xaa\x93\xc5\x9c\xc1\x76\xc4\xb7\x35\x5a\xf2\xf2\x3b\x04\xc0\x89\xba\x5b\x9a\xf1\xc7\x72\xfb\x6d\x53\x9e\xf2\xe7\xe5\xea\x35\x9a\xcb\x7b\x79\x44\xfc\xfa\xbc\x79\x48\xf6\x7d\x72\x9f\x39\xed\xe9\x7c\xe8\xf3\x84\x96\x2d\x07\xfc\xa5\x97\x94\x83\x15\xc4\x83\x88\x7f\xa1\x46\x41\xf6\xe7\xe2\xfe\xb1\xdc\x4a\x10\x03\x19\x6a\x1a\xe5\x49\x7f\x2e\x92\xa3\x0e\x5b\x2f\xe3\x5d\x6d\xa6\xe8\xcf\x4b\x0c\x17\x37\xd4\x1f\x4b\x16\xeb\xfa\x81\x52\xa0\xff\x44\xb3\x8b\x72\x80\xaf\x59\x06\xab\x51\x8c\x8d\x4f\xda\x2d\xb9\xea\xeb\xf9\x51\xc5\xce\x17\x7c\x9f\x7c\x85\x36\xab\x81\x7f\xbe\x0d\xb3\x09\x05\x28\x98\x30\x87\xbd\x0a\x4d\x58\x03\x11\x64\x28\x40\x06\xdf\x16\xd8\x68\xa9\x83\x0e\x06\x32\x14\xad\x14\x19\x8a\x5f\xa1\x66\x17\x1b\xe7\xc0\x3c\xf2\x3a\xb4\x99\xcd\xc1\xbe\xc2\x94\xfe\xc8\xc8\x5f\x83\xf9\xb8\xce\xb4\x2a\x64\x87\x3e\x82\x16\xb2\x1a\xfc\x8e\xac\x16\xd3\x65\xf1\xab\x85\x5c\x63\x13\x3f\x7e\x2c\x37\x02\x3f\x26\x19\x20\x12\xd9\x83\xcf\x5e\x32\x49\xf6\x91\x71\x32\x01\x22\x79\x8a\x3c\x4d\xf6\x93\x1c\xb2\x13\x64\xef\xfa\xd8\x4a\x6c\x45\x5e\x3c\x37\xd6\xfd\xbc\x8c\x33\x52\xa6\x9b\x45\xdd\x39\xb4\xbb\xa0\x60\xff\x33\x2a\x4c\x5c\x53\xd7\xac\x2c\x0e\xb6\x86\x23\xcb\x29\xfb\x05\x5d\xbd\xfc\xc6\x5f\xb1\x5c\xe9\x2c\x37\x51\xb6\xe2\x19\x9d\xba\x57\xce\xf9\x5f\xf9\xeb\xfc\x32\xf6\x2b\xfc\x6a\xa4\xe0\x7f\xe4\x57\xf1\xb7\x72\xc7\x5a\xcc\xbb\xb2\x4c\xc3\xec\x6c\x58\x73\x77\x55\x1a\x6d\x06\xe3\x16\xf0\xd1\x99\xc5\x89\xc5\x1d\xf3\x71\xf1\xe4\x57\x0f\x46\x7e\x96\xc9\x99\xe7\xaf\xf4\x5d\x3c\x59\x6f\x2e\x0e\x46\xac\x97\x05\xfa\x6a\xf9\x56\x19\x4e\x8d\x44\xac\xf4\x83\xf4\x87\xb4\x2c\xbd\x27\x7d\x28\xfd\xc6\xbd\xcd\x7d\xca\x7d\xcd\x7d\xce\x7d\xc1\x5d\x02\x91\x3b\xcb\x9d\xe3\xbe\xe1\x2e\x70\x1f\x73\x5f\xc6\xf6\x6a\xf3\x1a\x5a\xdf\x7b\x16\x79\x18\xb7\x67\xe9\x96\x6b\xac\x4a\x21\x23\x6c\x15\x1e\x16\x72\xc2\x36\xe1\x51\x61\x32\xf2\x27\x0c\x08\x63\x42\x51\xd8\x81\x96\xad\xeb\xfb\x16\x9f\x2f\x9e\x3d\x1d\x0e\x63\x1f\xe6\xa7\xfb\x5c\xbe\x2e\x56\x01\x89\xfb\xb1\x02\xf4\x4d\xfe\x55\x55\x54\xe9\x70\x94\x29\x1d\x56\x6f\x4d\x38\xbe\x41\x13\x8c\x24\x43\x64\x8c\x94\x36\x54\xf7\xb8\x57\xf3\xa1\x22\x95\x4f\xe5\x52\x69\x10\x53\x3b\x53\x13\xa9\xb1\xd4\x41\x0f\x87\xb3\xa6\x76\xa0\x6d\x02\xfb\xfc\x1d\xd5\xa9\x6e\xb2\x52\xea\xd2\x63\xde\x7d\x02\x59\xd3\x3a\x6e\xeb\x0d\xcd\x15\x77\x4b\xd2\x93\x62\x1a\xaf\x36\x2a\x96\x9a\xea\xe8\x88\xa8\x18\x86\xc8\x4c\x8e\x68\x53\x87\xda\x6d\x5a\x1b\x05\xef\xde\xf4\x8f\xf4\x9b\x32\xbb\x0f\x13\x5b\x2e\x47\x9c\xfb\x0c\xc0\xbe\x3f\xf1\xec\xfb\x2e\xe2\x8e\xb4\x00\x96\x1c\x80\x81\xc7\x23\x6e\x18\xcf\xca\x07\xde\x05\x38\xf3\x84\xda\xb2\xdb\xc1\x1d\x91\x48\x7c\x0b\xe0\xd4\xf7\xec\xf6\xbf\xfa\x32\x78\x7e\xfd\xd4\xe9\xdc\xc4\x73\xac\xe7\x2d\x80\xb5\x37\x3b\x9d\xbf\xdf\xef\x74\xd6\x3e\x40\xff\xab\x00\x67\x8d\x7f\x01\xa0\x9f\x7c\x55\x03\x5c\x0b\xef\x00\x00\x04\xc8\x49\x44\x41\x54\x58\x09\xbd\x58\x5b\x68\x1c\x55\x18\xfe\xce\x99\x99\xbd\x6f\x36\xdb\xe8\x12\x1b\xd3\x50\x14\x8a\x78\x01\xa1\x54\xe3\x0d\x24\x8a\xf4\xc1\x1b\x1a\xdf\x44\xf0\xc1\x67\x7d\x2a\x28\x48\x05\xa5\x3e\x8a\x2f\x22\xf8\x22\x45\xaa\x4f\xbe\x28\x82\x04\x7c\xf0\xc9\x1b\x56\x9b\xa6\xd4\x76\x49\x24\xb4\x9a\x8b\xd9\xec\x6d\x76\x76\x67\x66\xc7\xef\xcc\x6e\x36\x7b\x99\xdd\x34\xcd\x6e\x7f\x98\xdb\xb9\xfc\xff\xf7\x5f\xce\x7f\xfe\x33\x02\x80\xc7\xab\x2f\x25\x32\x31\x4c\xcf\xcf\x40\x8f\x6b\xf0\xea\x1e\xec\xff\xaa\xc8\x5f\x2e\xa1\x70\xa9\x00\x33\x67\x06\xce\x1b\x3b\x32\x86\xdb\x8e\xa7\x11\x99\x8e\x43\x37\x24\x8a\x57\x8b\x58\xf9\x76\x15\x9e\x5d\x0f\x1c\xaf\x1a\xf5\xbe\x3d\xcd\x8e\x5a\xd9\x46\x38\xa5\x61\xe2\xf8\x04\xdc\x6a\x1d\x42\x00\x87\x9f\x11\xa8\x6c\x59\xb8\xf6\xcd\xbf\xf8\xe7\x87\xeb\x70\x2a\x8e\x3f\x5a\x0f\x49\xcc\x3c\x3b\x83\xc3\xcf\x4f\x42\x0b\x1b\x00\x81\xeb\x11\x0d\xd5\xbc\x3d\x10\x84\x9a\xac\xf1\x3a\xad\x5e\xfa\x91\x4b\x2d\xb6\x2f\x14\xa1\x4f\x46\x79\x85\x00\xa5\x95\x53\x87\x16\xd5\xa8\xf5\x04\x22\x93\x31\x24\xfe\xb2\x70\xa7\x66\xe0\xd0\x4b\xd3\x98\x7a\x79\x1a\x75\xf6\xab\x31\x9e\x46\x6b\x2c\x97\x91\xfd\x2c\x0b\xbb\x62\xf7\x13\xe1\xb7\x53\xbf\x3d\x5c\xa3\xeb\xb8\xdd\x30\x70\x24\x19\xc2\xb1\xe7\xa6\xb0\x32\x3b\x8e\x7c\x54\x42\x72\x9a\x50\x96\x0e\x6b\x78\xe0\xa2\x89\xb9\x4b\x55\x7c\xf4\x62\x1a\x75\xd7\xf5\x19\x6a\x52\xc2\xa6\xfb\x16\x3f\xcd\xc2\x5c\x2b\x0d\x04\xa1\x3a\x03\x81\xc4\x74\x81\x87\x13\x49\xcc\xc7\xe3\x78\x22\x62\xe0\xa8\x34\x60\x68\x02\x82\x4a\x2d\x67\x74\x2c\x1c\x0b\x61\x71\x3a\x84\xad\x31\xc6\x8d\xf4\x90\x2a\x78\x98\xca\x39\xc8\xb2\xcf\xe1\xdc\x4c\xde\xc5\xe3\x57\x6b\xb8\xe3\x7c\x01\x3f\xe7\x4d\xb8\xba\xc4\x8a\x6d\xe3\xd7\xaa\x8d\x8b\x96\x85\x7c\xad\xd7\x3a\x3d\x40\x9e\x4e\x25\x71\x7a\x3c\x8d\x13\xb1\x48\x43\x0b\x4f\xc5\xf2\x6e\x3c\x0b\x97\xef\x0e\xa7\xe9\x1e\x5c\x9a\x1e\x04\xa2\xd9\xcd\x7e\x06\x90\xc7\x26\x7f\x4c\x9d\xef\x0c\x13\x08\xd5\xd0\x60\x05\xf2\xba\x62\x55\xf1\x71\xa9\x8c\xb3\xb9\x1c\xca\xca\x85\x4d\x6a\x01\x09\x49\x81\xb7\x33\x19\xbc\x93\x1e\x6b\x74\xf9\x00\x76\x86\x75\x3e\x45\x8d\xc1\xa9\x98\x48\x86\x98\xd6\x04\xa1\x1e\xaa\x8d\xda\x83\xee\x54\x80\x82\x89\x22\x29\xeb\xbb\x62\x19\xaf\x6f\x6c\x60\xd3\xaa\xf9\xc3\x5a\x40\xde\x9f\xcc\xe0\xd4\xa1\x71\x46\xfa\x2e\xca\x60\x46\x43\x6a\x65\x0c\x2d\x14\x4d\xcc\x5f\xbf\x86\x12\xad\xec\xe3\x3e\x39\x9e\xc2\xa9\xf4\x2d\x04\xa1\x74\xa1\xc2\x4f\x25\x63\x78\x55\x29\x4f\x92\x09\x9a\xf1\x8c\xfa\x18\xe0\x0a\x7f\xe4\x28\x6e\xcc\x33\x6f\x25\x93\x48\x85\x0c\xc8\x13\xf1\x18\xee\x0d\x87\x29\xa6\xe9\xeb\x51\x08\xec\xcb\xd3\xc3\x51\xca\xbe\x2f\x12\x81\x3c\x19\x8b\xee\x46\x75\xd7\x04\x61\xb9\x5d\x2d\x37\xff\xd9\x97\x17\x57\xda\xa3\x51\x02\x79\x30\xc4\x2c\x4f\x13\x05\x91\xc7\xf4\x3c\x2c\x52\xbc\x44\x90\x1c\x8a\xbe\x9f\x09\x53\x4e\x89\x3d\xb7\x9b\x61\x61\x01\xd4\xb2\xef\x26\xc6\xe6\x5d\xba\x06\x29\xb9\xa6\x6f\x19\x05\x59\x84\xc2\x27\x98\x8f\x64\xa0\xb9\x46\x85\x2c\xd2\xdf\xfa\xb2\x2c\x7a\xe3\x43\x8c\x28\xa9\x79\x5c\x18\xfd\x78\xcb\x35\xee\x96\xad\xbd\x60\x54\x96\x68\xf2\xe5\xb6\x19\x28\xc1\x81\x0b\xb9\xe4\x30\x80\x54\xb5\xd3\x41\xdd\xdf\x1d\x9d\x37\xfd\xe1\x55\xab\x9c\xdb\xcb\x7b\xdd\x15\x90\xbf\x57\x1b\x9b\x4e\x3b\x77\x6f\x44\x01\x2c\x2a\x55\x6e\x86\x5d\x40\x68\x84\xac\x53\x83\xfc\x8d\x28\x3d\x3b\x60\x59\xb5\x23\x1b\xe5\x3b\x71\xfd\x42\x63\xc8\x15\xab\x82\x25\x15\x27\x01\x26\x1b\xba\x7c\x23\x60\xd5\x70\x49\xff\xa4\x80\x98\x8e\x87\xaf\xca\x2c\xe5\xba\x4d\xa6\xa0\x35\x6b\x85\x61\x00\x52\xbc\x54\x0d\xdb\x49\x02\x8b\xf4\xc8\x95\x8a\xd9\x28\x03\xbe\x64\x5d\x60\xb9\xbd\xee\xf1\x58\x26\x0e\x8b\x02\x79\x51\xf9\xcf\xcb\x65\xbf\x52\xf3\x21\x2e\x9b\x15\x9c\x65\xc5\xc4\x3c\xdb\x25\xb7\x2b\xb0\xba\x7a\xf7\xf7\xd9\xcb\x6b\x83\x35\xec\x39\x25\x97\xd4\x92\x7c\x66\xbb\x80\x1c\x3b\x82\x62\x45\xb8\x23\xa8\xda\xa8\xf4\xbb\xf9\x02\xd6\x2a\x56\x27\x90\x55\x5a\xe5\xcd\xed\xed\xc0\x58\xe9\xf5\xad\x3f\x77\xe0\x4d\x98\xb5\xe0\xdd\x56\xcd\x62\x41\xad\x6a\xd6\x2f\xb6\x72\x2d\x1e\x1d\x07\xac\x0b\x44\x17\xe7\x06\xf4\x48\x9c\x35\xca\x41\x2b\x36\x83\xac\x7b\x12\x65\x03\xc4\x65\xcb\xc6\x2b\x2c\x9c\x73\x6d\xc7\x8a\x96\x6b\x76\xa0\xbd\xb7\xb1\x89\x4f\xe8\xa6\xde\x78\xd9\x19\x71\x80\x27\xdd\xf1\x07\xcf\x35\x2f\xac\xf3\xa8\x4a\x0f\xb4\x53\x87\x45\x54\x87\x43\x4b\x2c\xf0\xdc\x51\xa0\x36\x4f\xf2\x6c\x23\x83\xb4\x6a\xe7\x70\x43\xef\x0c\x54\xba\xe3\x5c\xb1\x84\xd7\x36\xd6\xb1\x6a\xaa\x54\xdf\x49\x2a\x94\x7b\xb7\xdf\xe6\x98\x87\xc6\x12\xf8\x30\x3d\x81\xc7\xe2\xac\x69\x95\xab\xf6\xeb\x2e\xa5\x04\xaf\x3f\x99\x43\x3e\xe0\x81\xea\x6b\x5a\xda\xeb\xc3\x63\x20\x10\x85\x47\x1d\x3f\xe7\x92\x29\xbc\x91\x8a\x63\xce\x88\xc2\xe0\x6f\x06\x1f\x7a\x8b\xa1\xd2\x43\xb1\x69\x3e\x94\x70\x36\x95\x98\xad\x7f\x64\xcc\xa9\x3c\xf1\x3d\x2d\x51\xdc\x63\x1b\xd9\x13\x48\x43\x42\xe3\x7e\x37\x83\x78\x96\x15\xf7\x6c\x38\x8a\x7b\x0c\x81\x29\x4d\x43\x92\xa5\x66\x0d\x75\x6c\x7a\x75\xfc\x4d\x61\x4b\xbc\x54\xca\x3e\xcf\x8c\xa9\x56\xe2\x8d\xd2\xbe\x80\xb4\x33\x15\xd4\x3c\xc1\x5a\x33\xc4\xb4\xcd\x1f\x10\xb0\x6a\x2e\x2a\x07\x28\xa8\xfe\x07\xc2\xd0\xce\x29\xe3\xbd\xb1\xa3\x00\x00\x00\x00\x49\x45\x4e\x44\xae\x42\x60\x82\x03\x00\x49\x4b\xd6\xbe\x26\x09\x00\x00")
 
 func red_png() ([]byte, error) {
 	return bindata_read(
@@ -66,6 +75,7 @@ func AssetNames() []string {
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() ([]byte, error){
 	"green.png": green_png,
+	"orange.png": orange_png,
 	"red.png": red_png,
 }
 // AssetDir returns the file names below a certain
@@ -110,6 +120,8 @@ type _bintree_t struct {
 var _bintree = &_bintree_t{nil, map[string]*_bintree_t{
 	"green.png": &_bintree_t{green_png, map[string]*_bintree_t{
 	}},
+	"orange.png": &_bintree_t{orange_png, map[string]*_bintree_t{
+	}},
 	"red.png": &_bintree_t{red_png, map[string]*_bintree_t{
 	}},
 }}